@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Breakpoint modes: add whole copies of the attacking unit, or models within its legal size
+const (
+	_breakpointCopies = "copies"
+	_breakpointModels = "models"
+)
+
+// Index of the model group that grows with the unit's size, or -1 if it has none
+func (u *Unit) sizeGroup() int {
+	if u.Size == nil || len(u.Models) == 0 {
+		return -1
+	}
+	if u.Size.Model != "" {
+		for i, model := range u.Models {
+			if model.Name == u.Size.Model {
+				return i
+			}
+		}
+		return -1
+	}
+	largest := 0
+	for i, model := range u.Models {
+		if model.Count > u.Models[largest].Count {
+			largest = i
+		}
+	}
+	return largest
+}
+
+// Attacker resized to a legal number of models by growing or shrinking its size group, with
+// the points it costs. Each model is priced at the unit's cost per starting model.
+func resizeAttacker(base Unit, models int) (Unit, int, error) {
+	group := base.sizeGroup()
+	if group < 0 {
+		return Unit{}, 0, fmt.Errorf("%s has no unit_size model group '%s'", base.Name, base.Size.Model)
+	}
+	attacker := base.Clone()
+	count := models - (base.startingStrength() - base.Models[group].Count)
+	if count < 1 {
+		return Unit{}, 0, fmt.Errorf("%s cannot have %d models, its other model groups already have more", base.Name, models)
+	}
+	attacker.Models[group].Count = count
+	cost := float64(base.Cost) * float64(attacker.startingStrength()) / float64(base.startingStrength())
+	return attacker, int(math.Round(cost)), nil
+}
+
+// BreakpointStep is the outcome of one attacker size or number of copies
+type BreakpointStep struct {
+	Models     int
+	Points     int
+	KillChance float64
+	MeanDamage float64
+}
+
+// Attack with several copies of the unit one after another, carrying wounds between them
+func copiesStep(base Unit, copies int, defender Unit, scenario Scenario, workers int, seed int64) BreakpointStep {
+	order := make([]FocusAttacker, copies)
+	for i := range order {
+		order[i] = FocusAttacker{Label: base.Name, Unit: base}
+	}
+	results := runFocusSimulations(order, defender, scenario, _numSimulations, workers, seed)
+
+	step := BreakpointStep{Models: base.startingStrength() * copies, Points: base.Cost * copies}
+	for _, result := range results {
+		if result.Destroyed {
+			step.KillChance++
+		}
+		for _, damage := range result.Damage {
+			step.MeanDamage += float64(damage)
+		}
+	}
+	step.KillChance /= float64(len(results))
+	step.MeanDamage /= float64(len(results))
+	return step
+}
+
+// Attack with the unit resized to a number of models
+func modelsStep(base Unit, models int, defender Unit, scenario Scenario, workers int, seed int64) (BreakpointStep, error) {
+	attacker, points, err := resizeAttacker(base, models)
+	if err != nil {
+		return BreakpointStep{}, err
+	}
+	conflict := UnitAttackSequence{Attacker: attacker, Defender: defender, Scenario: scenario}
+	summary := summariseMatchup(runSimulations(conflict, _numSimulations, workers, seed, nil), attacker, defender)
+	return BreakpointStep{Models: models, Points: points, KillChance: summary.KillChance, MeanDamage: summary.MeanDamage}, nil
+}
+
+// Find how many copies or legal models of each attacker are needed to destroy each defender
+// with at least the given confidence. Copies attack one after another, each a separate unit,
+// up to the limit; models mode tries every legal unit size and skips units without a unit_size.
+func runBreakpoints(attackerSpec, defenderSpec, mode string, confidence float64, limit int, scenario Scenario, workers int, seed int64) error {
+	if mode != _breakpointCopies && mode != _breakpointModels {
+		return fmt.Errorf("unknown breakpoint mode '%s', expected %s or %s", mode, _breakpointCopies, _breakpointModels)
+	}
+	if confidence <= 0 || confidence > 1 {
+		return fmt.Errorf("confidence %.2f is outside 0-1", confidence)
+	}
+
+	attackers, err := selectUnits(attackerSpec)
+	if err != nil {
+		return err
+	}
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	attackerLabels := matrixLabels(attackers)
+	defenderLabels := matrixLabels(defenders)
+
+	// Models mode only searches legal sizes, so attackers without one are skipped
+	if mode == _breakpointModels {
+		for a, attackerFile := range attackers {
+			unit := loadUnit(attackerFile)
+			if unit.Size != nil && (unit.Size.Min < 1 || unit.Size.Max < unit.Size.Min) {
+				return fmt.Errorf("%s has an invalid unit_size of %d-%d models", attackerLabels[a], unit.Size.Min, unit.Size.Max)
+			}
+		}
+	}
+
+	for a, attackerFile := range attackers {
+		if mode == _breakpointModels && loadUnit(attackerFile).Size == nil {
+			fmt.Printf("Skipping %s: it has no unit_size, so its legal model counts are unknown\n\n", attackerLabels[a])
+			continue
+		}
+		for d, defenderFile := range defenders {
+			base := loadUnit(attackerFile)
+			fmt.Printf("=== Breakpoint: %s against %s (%.0f%% to destroy) ===\n", attackerLabels[a], defenderLabels[d], 100*confidence)
+			if mode == _breakpointCopies {
+				fmt.Printf("%6s  ", "Copies")
+			}
+			fmt.Printf("%6s  %6s  %9s  %11s\n", "Models", "Points", "Destroyed", "Mean damage")
+
+			first, last := 1, limit
+			if mode == _breakpointModels {
+				first, last = base.Size.Min, base.Size.Max
+			}
+			found := false
+			for n := first; n <= last; n++ {
+				var step BreakpointStep
+				if mode == _breakpointCopies {
+					step = copiesStep(base, n, loadUnit(defenderFile), scenario, workers, seed)
+					fmt.Printf("%6d  ", n)
+				} else if step, err = modelsStep(base, n, loadUnit(defenderFile), scenario, workers, seed); err != nil {
+					return err
+				}
+				fmt.Printf("%6d  %6d  %8.1f%%  %11.2f\n", step.Models, step.Points, 100*step.KillChance, step.MeanDamage)
+
+				if step.KillChance >= confidence {
+					needed := fmt.Sprintf("%d models", step.Models)
+					if mode == _breakpointCopies {
+						needed = fmt.Sprintf("%d copies, %d models", n, step.Models)
+					}
+					fmt.Printf("Needs %s (%d points) for a %.1f%% chance to destroy %s\n",
+						needed, step.Points, 100*step.KillChance, defenderLabels[d])
+					found = true
+					break
+				}
+			}
+			if !found && mode == _breakpointCopies {
+				fmt.Printf("Not reached within %d copies\n", limit)
+			} else if !found {
+				fmt.Printf("Not reached by any legal unit size (%d-%d models)\n", base.Size.Min, base.Size.Max)
+			}
+			fmt.Printf("\n")
+		}
+	}
+	return nil
+}
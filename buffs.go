@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WeaponBuff is a single improvement to every weapon of a unit. Each buff is also an attacker
+// ability of the same name, so it can be given to a unit in its YAML.
+type WeaponBuff struct {
+	Ability string
+	Label   string
+	Apply   func(WeaponProfile) WeaponProfile
+}
+
+var _weaponBuffs = []WeaponBuff{
+	{"+1 to hit", "+1 to hit", func(w WeaponProfile) WeaponProfile {
+		w.Modifiers.HitMod = capRollModifier(w.Modifiers.HitMod + 1)
+		return w
+	}},
+	{"+1 to wound", "+1 to wound", func(w WeaponProfile) WeaponProfile {
+		w.Modifiers.WoundMod = capRollModifier(w.Modifiers.WoundMod + 1)
+		return w
+	}},
+	{"reroll hits", "Reroll hits", func(w WeaponProfile) WeaponProfile { w.Modifiers.RerollHits = true; return w }},
+	{"reroll wounds", "Reroll wounds", func(w WeaponProfile) WeaponProfile { w.Modifiers.RerollWounds = true; return w }},
+	{"lethal hits", "Lethal Hits", func(w WeaponProfile) WeaponProfile { return addWeaponKeyword(w, "Lethal Hits") }},
+	{"sustained hits 1", "Sustained Hits 1", func(w WeaponProfile) WeaponProfile { return addWeaponKeyword(w, "Sustained Hits 1") }},
+	{"+1 ap", "-1 AP", func(w WeaponProfile) WeaponProfile {
+		w.Characteristics["AP"] = addToCharacteristic(w.GetStringCharacteristic("AP"), -1)
+		return w
+	}},
+	{"+1 damage", "+1 damage", func(w WeaponProfile) WeaponProfile {
+		w.Characteristics["D"] = addToCharacteristic(w.GetStringCharacteristic("D"), 1)
+		return w
+	}},
+	{"devastating wounds", "Devastating Wounds", func(w WeaponProfile) WeaponProfile { return addWeaponKeyword(w, "Devastating Wounds") }},
+}
+
+// Look up the buff an attacker ability grants, if any
+func weaponBuffFor(ability string) (WeaponBuff, bool) {
+	for _, buff := range _weaponBuffs {
+		if strings.EqualFold(strings.TrimSpace(ability), buff.Ability) {
+			return buff, true
+		}
+	}
+	return WeaponBuff{}, false
+}
+
+// Add a keyword to the weapon unless it already has it. Sustained Hits is matched on the
+// keyword alone, so an existing Sustained Hits 2 is not replaced.
+func addWeaponKeyword(weapon WeaponProfile, keyword string) WeaponProfile {
+	keywords := weapon.GetStringCharacteristic("Keywords")
+	name := strings.ToLower(strings.TrimSuffix(keyword, " 1"))
+	if strings.Contains(strings.ToLower(keywords), name) {
+		return weapon
+	}
+	if strings.TrimSpace(keywords) != "" {
+		keywords += ", " + keyword
+	} else {
+		keywords = keyword
+	}
+	weapon.Characteristics["Keywords"] = keywords
+	return weapon
+}
+
+var _diceExpression = regexp.MustCompile(`^(\d*[dD]\d+)\s*(?:([+-])\s*(\d+))?$`)
+
+// Add to a flat or dice characteristic, e.g. "2" becomes "3" and "D6+1" becomes "D6+2".
+// AP is written as a negative number, so -1 improves it.
+func addToCharacteristic(value string, delta int) string {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		value = "0"
+	}
+	if number, err := strconv.Atoi(value); err == nil {
+		return strconv.Itoa(number + delta)
+	}
+	matches := _diceExpression.FindStringSubmatch(value)
+	if matches == nil {
+		return value
+	}
+	modifier, _ := strconv.Atoi(matches[3])
+	if matches[2] == "-" {
+		modifier = -modifier
+	}
+	modifier += delta
+	switch {
+	case modifier > 0:
+		return fmt.Sprintf("%s+%d", matches[1], modifier)
+	case modifier < 0:
+		return fmt.Sprintf("%s-%d", matches[1], -modifier)
+	}
+	return matches[1]
+}
+
+// Copy of the attacker with a buff given as an ability
+func buffedAttacker(base Unit, buff WeaponBuff) Unit {
+	attacker := base.Clone()
+	attacker.Abilities = append(append([]string(nil), base.Abilities...), buff.Ability)
+	attacker.UnitAbilities = attacker.Abilities
+	return attacker
+}
+
+// Check whether every weapon the attacker fires already has the buff, so adding it would
+// change nothing, including a +1 to hit or wound modifier already at its cap. Stratagems are left out, as the attacker may only use them once.
+func (conflict *UnitAttackSequence) hasWeaponBuff(buff WeaponBuff) bool {
+	view := conflict.deriveWeaponView(false)
+	weapons := 0
+	for _, loadouts := range view.Loadouts {
+		for _, weapon := range loadouts {
+			if !reflect.DeepEqual(buff.Apply(weapon.clone()), weapon) {
+				return false
+			}
+			weapons++
+		}
+	}
+	return weapons > 0
+}
+
+// BuffSensitivity is the change a single buff makes to a matchup
+type BuffSensitivity struct {
+	Buff       WeaponBuff
+	Summary    MatchupSummary
+	AlreadyHas bool // The attacker already has the buff, so it was not simulated
+}
+
+// Rerun each attacker against each defender with every buff applied alone, on the same seed,
+// and report how much each one changes the mean damage and the chance to destroy the unit
+func runSensitivity(attackerSpec, defenderSpec string, scenario Scenario, workers int, seed int64) error {
+	attackers, err := selectUnits(attackerSpec)
+	if err != nil {
+		return err
+	}
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	attackerLabels := matrixLabels(attackers)
+	defenderLabels := matrixLabels(defenders)
+
+	for a, attackerFile := range attackers {
+		base := loadUnit(attackerFile)
+		for d, defenderFile := range defenders {
+			conflict := UnitAttackSequence{
+				Attacker: base,
+				Defender: loadUnit(defenderFile),
+				Scenario: scenario,
+			}
+			baseline := summariseMatchup(runSimulations(conflict, _numSimulations, workers, seed, nil), conflict.Attacker, conflict.Defender)
+
+			sensitivities := []BuffSensitivity{}
+			for _, buff := range _weaponBuffs {
+				if conflict.hasWeaponBuff(buff) {
+					sensitivities = append(sensitivities, BuffSensitivity{Buff: buff, AlreadyHas: true})
+					continue
+				}
+				conflict := UnitAttackSequence{
+					Attacker: buffedAttacker(base, buff),
+					Defender: loadUnit(defenderFile),
+					Scenario: scenario,
+				}
+				results := runSimulations(conflict, _numSimulations, workers, seed, nil)
+				sensitivities = append(sensitivities, BuffSensitivity{Buff: buff, Summary: summariseMatchup(results, conflict.Attacker, conflict.Defender)})
+			}
+			sort.SliceStable(sensitivities, func(i, j int) bool {
+				if sensitivities[i].AlreadyHas != sensitivities[j].AlreadyHas {
+					return sensitivities[j].AlreadyHas
+				}
+				return sensitivities[i].Summary.MeanDamage > sensitivities[j].Summary.MeanDamage
+			})
+
+			fmt.Printf("=== Buff sensitivity: %s against %s ===\n", attackerLabels[a], defenderLabels[d])
+			fmt.Printf("%-20s  %11s  %8s  %9s  %9s\n", "Buff", "Mean damage", "Change", "Destroyed", "Change")
+			fmt.Printf("%-20s  %11.2f  %8s  %8.1f%%  %9s\n", "None", baseline.MeanDamage, "", 100*baseline.KillChance, "")
+			for _, sensitivity := range sensitivities {
+				if sensitivity.AlreadyHas {
+					fmt.Printf("%-20s  %11s\n", sensitivity.Buff.Label, "Already has")
+					continue
+				}
+				summary := sensitivity.Summary
+				fmt.Printf("%-20s  %11.2f  %+8.2f  %8.1f%%  %+8.1f%%\n",
+					sensitivity.Buff.Label,
+					summary.MeanDamage,
+					summary.MeanDamage-baseline.MeanDamage,
+					100*summary.KillChance,
+					100*(summary.KillChance-baseline.KillChance))
+			}
+			fmt.Printf("\n")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestAddToCharacteristic(t *testing.T) {
+	tests := []struct {
+		value string
+		delta int
+		want  string
+	}{
+		{"2", 1, "3"},
+		{"-1", -1, "-2"},
+		{"0", -1, "-1"},
+		{"-", -1, "-1"},
+		{"", 1, "1"},
+		{"D3", 1, "D3+1"},
+		{"D6+1", 1, "D6+2"},
+		{"2D6-1", 1, "2D6"},
+		{"d6 + 2", -3, "d6-1"},
+		{"N/A", 1, "N/A"},
+	}
+	for _, test := range tests {
+		if got := addToCharacteristic(test.value, test.delta); got != test.want {
+			t.Errorf("addToCharacteristic(%q, %d) = %q, want %q", test.value, test.delta, got, test.want)
+		}
+	}
+}
+
+func TestHasWeaponBuff(t *testing.T) {
+	weapon := WeaponProfile{Name: "Bolt Rifle", Characteristics: map[string]string{"A": "2", "D": "1", "Keywords": "Heavy, Lethal Hits"}}
+	conflict := UnitAttackSequence{Attacker: Unit{
+		Abilities: []string{"Oath of Moment", "Stationary"},
+		Models:    []ModelData{{Name: "Marine", Count: 1, Loadouts: map[string]WeaponProfile{"Bolt Rifle": weapon}}},
+	}}
+	tests := []struct {
+		ability string
+		want    bool
+	}{
+		{"reroll hits", true},
+		{"+1 to hit", true},
+		{"+1 to wound", false},
+		{"lethal hits", true},
+		{"sustained hits 1", false},
+		{"+1 damage", false},
+	}
+	for _, test := range tests {
+		buff, _ := weaponBuffFor(test.ability)
+		if got := conflict.hasWeaponBuff(buff); got != test.want {
+			t.Errorf("hasWeaponBuff(%s) = %v, want %v", test.ability, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConformanceCase pins a rules interaction to an exact sequence of dice. The attacker
+// attacks once through loadoutAttackSequence, and the recorded outcome is compared with
+// the expectations. Units are written inline or taken from ./library/.
+type ConformanceCase struct {
+	Name         string                 `yaml:"name"`
+	Description  string                 `yaml:"description,omitempty"`
+	Attacker     *Unit                  `yaml:"attacker,omitempty"`
+	Defender     *Unit                  `yaml:"defender,omitempty"`
+	AttackerFile string                 `yaml:"attacker_file,omitempty"` // Library unit used when no inline attacker is given
+	DefenderFile string                 `yaml:"defender_file,omitempty"`
+	Scenario     Scenario               `yaml:"scenario,omitempty"`
+	Dice         []int                  `yaml:"dice"` // Every die rolled, in order; all of them must be used
+	Expect       ConformanceExpectation `yaml:"expect"`
+}
+
+// ConformanceExpectation lists the outcomes a case checks, omitted ones are not checked
+type ConformanceExpectation struct {
+	Hits        *int `yaml:"hits,omitempty"`
+	Wounds      *int `yaml:"wounds,omitempty"`
+	Saves       *int `yaml:"saves,omitempty"`
+	FailedSaves *int `yaml:"failed_saves,omitempty"`
+	Damage      *int `yaml:"damage,omitempty"`
+	Kills       *int `yaml:"kills,omitempty"`
+}
+
+// scriptedDice replays a fixed list of results in place of random rolls
+type scriptedDice struct {
+	rolls []int
+	next  int
+	err   error
+}
+
+func (d *scriptedDice) Intn(n int) int {
+	if d.next >= len(d.rolls) {
+		if d.err == nil {
+			d.err = fmt.Errorf("ran out of dice after %d rolls", len(d.rolls))
+		}
+		return 0
+	}
+	roll := d.rolls[d.next]
+	d.next++
+	if roll < 1 || roll > n {
+		if d.err == nil {
+			d.err = fmt.Errorf("die %d is %d, but a D%d was rolled", d.next, roll, n)
+		}
+		return 0
+	}
+	return roll - 1
+}
+
+func loadConformanceCase(path string) (ConformanceCase, error) {
+	testCase := ConformanceCase{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return testCase, err
+	}
+	if err = yaml.Unmarshal(data, &testCase); err != nil {
+		return testCase, err
+	}
+	if testCase.Name == "" {
+		testCase.Name = strings.TrimSuffix(filepath.Base(path), ".yaml")
+	}
+	if testCase.Scenario.Terrain, err = normaliseTerrain(testCase.Scenario.Terrain); err != nil {
+		return testCase, err
+	}
+	return testCase, nil
+}
+
+// Resolve a case's attacking or defending unit, inline units take precedence
+func conformanceUnit(inline *Unit, file, side string) (Unit, error) {
+	if inline != nil {
+		return prepareUnit(*inline, side), nil
+	}
+	if file == "" {
+		return Unit{}, fmt.Errorf("no %s given", side)
+	}
+	return loadUnit(file), nil
+}
+
+// Run a single case, returning every way it differed from its expectations
+func (testCase ConformanceCase) run() []string {
+	attacker, err := conformanceUnit(testCase.Attacker, testCase.AttackerFile, "attacker")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defender, err := conformanceUnit(testCase.Defender, testCase.DefenderFile, "defender")
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	dice := &scriptedDice{rolls: testCase.Dice}
+	conflict := UnitAttackSequence{
+		Attacker: attacker,
+		Defender: defender,
+		Scenario: testCase.Scenario,
+		dice:     dice,
+	}
+	_, damage := conflict.loadoutAttackSequence()
+
+	failures := []string{}
+	if dice.err != nil {
+		failures = append(failures, dice.err.Error())
+	} else if unused := len(dice.rolls) - dice.next; unused > 0 {
+		failures = append(failures, fmt.Sprintf("%d of %d dice were not rolled", unused, len(dice.rolls)))
+	}
+
+	check := func(outcome string, expected *int, actual int) {
+		if expected != nil && *expected != actual {
+			failures = append(failures, fmt.Sprintf("%s: expected %d, got %d", outcome, *expected, actual))
+		}
+	}
+	check("hits", testCase.Expect.Hits, conflict.tally.Hits)
+	check("wounds", testCase.Expect.Wounds, conflict.tally.Wounds)
+	check("saves", testCase.Expect.Saves, conflict.tally.Saves)
+	check("failed_saves", testCase.Expect.FailedSaves, conflict.tally.FailedSaves)
+	check("damage", testCase.Expect.Damage, damage)
+	check("kills", testCase.Expect.Kills, conflict.Defender.ModelsLost())
+	return failures
+}
+
+// Run every case in a directory, printing the results and returning how many failed
+func runConformance(dir string) int {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(paths)
+
+	failed := 0
+	for _, path := range paths {
+		testCase, err := loadConformanceCase(path)
+		var failures []string
+		if err != nil {
+			failures = []string{err.Error()}
+		} else {
+			failures = testCase.run()
+		}
+
+		if len(failures) == 0 {
+			fmt.Printf("PASS %s\n", testCase.Name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s (%s)\n", testCase.Name, filepath.Base(path))
+		for _, failure := range failures {
+			fmt.Printf("     %s\n", failure)
+		}
+	}
+
+	fmt.Printf("%d cases, %d failed\n", len(paths), failed)
+	return failed
+}
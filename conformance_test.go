@@ -0,0 +1,32 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// Every rules conformance case must produce exactly its expected outcome
+func TestConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("conformance", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no conformance cases found")
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			testCase, err := loadConformanceCase(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, failure := range testCase.run() {
+				t.Errorf("%s: %s", testCase.Name, failure)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DamagedProfile degrades a model's attacks once its remaining wounds fall into the bracket
+type DamagedProfile struct {
+	Min        int `yaml:"min"`
+	Max        int `yaml:"max"`
+	HitMod     int `yaml:"hit_mod,omitempty"`     // Defaults to -1 when no effect is declared
+	WoundMod   int `yaml:"wound_mod,omitempty"`   // Modifier to wound rolls
+	AttacksMod int `yaml:"attacks_mod,omitempty"` // Modifier to each weapon's Attacks characteristic
+}
+
+var damagedPattern = regexp.MustCompile(`(?i)damaged:\s*(\d+)\s*-\s*(\d+)\s*wounds? remaining`)
+
+// Build the damaged profile from an explicit field or from the "Damaged: 1-6 wounds remaining" ability
+func parseDamagedProfile(unit Unit) *DamagedProfile {
+	profile := unit.Damaged
+	if profile == nil {
+		for _, ability := range unit.Abilities {
+			matches := damagedPattern.FindStringSubmatch(ability)
+			if matches == nil {
+				continue
+			}
+			profile = &DamagedProfile{}
+			profile.Min, _ = strconv.Atoi(matches[1])
+			profile.Max, _ = strconv.Atoi(matches[2])
+			break
+		}
+	}
+	if profile == nil {
+		return nil
+	}
+
+	// The standard Damaged effect is -1 to hit
+	if profile.HitMod == 0 && profile.WoundMod == 0 && profile.AttacksMod == 0 {
+		profile.HitMod = -1
+	}
+	return profile
+}
+
+// Check whether a model group's wounded model is inside its unit's damaged bracket. The
+// degraded profile applies to the whole group's attacks, so it is only used once a single
+// model of the group is left alive; a wounded model among healthy ones attacks normally.
+func (u *Unit) isDamaged(model ModelData) bool {
+	if u.Damaged == nil || model.Count-model.Killed != 1 {
+		return false
+	}
+	remaining := model.Wounds - model.CarryOverWounds
+	return remaining >= u.Damaged.Min && remaining <= u.Damaged.Max
+}
+
+// Describe the damaged bracket for logging
+func (p *DamagedProfile) String() string {
+	effects := []string{}
+	if p.HitMod != 0 {
+		effects = append(effects, fmt.Sprintf("%+d to hit", p.HitMod))
+	}
+	if p.WoundMod != 0 {
+		effects = append(effects, fmt.Sprintf("%+d to wound", p.WoundMod))
+	}
+	if p.AttacksMod != 0 {
+		effects = append(effects, fmt.Sprintf("%+d attacks", p.AttacksMod))
+	}
+	return fmt.Sprintf("%d-%d wounds remaining: %s", p.Min, p.Max, strings.Join(effects, ", "))
+}
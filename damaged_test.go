@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestParseDamagedProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		unit Unit
+		want *DamagedProfile
+	}{
+		{"no bracket", Unit{Abilities: []string{"Deadly Demise 1"}}, nil},
+		{"ability bracket", Unit{Abilities: []string{"Damaged: 1-4 Wounds Remaining"}}, &DamagedProfile{Min: 1, Max: 4, HitMod: -1}},
+		{"single wound bracket", Unit{Abilities: []string{"damaged: 1-6 wound remaining"}}, &DamagedProfile{Min: 1, Max: 6, HitMod: -1}},
+		{"explicit effect is kept", Unit{Damaged: &DamagedProfile{Min: 1, Max: 5, AttacksMod: -1}}, &DamagedProfile{Min: 1, Max: 5, AttacksMod: -1}},
+		{"explicit bracket wins over the ability", Unit{
+			Abilities: []string{"Damaged: 1-4 Wounds Remaining"},
+			Damaged:   &DamagedProfile{Min: 1, Max: 8, WoundMod: -1},
+		}, &DamagedProfile{Min: 1, Max: 8, WoundMod: -1}},
+	}
+	for _, test := range tests {
+		got := parseDamagedProfile(test.unit)
+		if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
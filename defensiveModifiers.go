@@ -0,0 +1,138 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefensiveModifiers collects the defender's rules that change the attacker's hit and
+// wound rolls. They are applied per attack and never written back into weapon data.
+type DefensiveModifiers struct {
+	RangedHitMod   int  // e.g. Stealth: -1 to be hit by ranged attacks
+	MeleeHitMod    int  // e.g. -1 to be hit in melee
+	WoundMod       int  // -1 to be wounded
+	WoundModStrong int  // -1 to be wounded when Strength exceeds Toughness
+	NoHitRerolls   bool // Hit rolls against this unit cannot be re-rolled
+	NoWoundRerolls bool // Wound rolls against this unit cannot be re-rolled
+	MinWoundRoll   int  // Transhuman style: only wounded on this unmodified roll or better
+	Sources        []string
+}
+
+var (
+	hitPenaltyPattern   = regexp.MustCompile(`(?i)-(\d) to (?:be )?hit(?: rolls?)?(?: (?:in|for|against|by) (melee|ranged))?`)
+	woundPenaltyPattern = regexp.MustCompile(`(?i)-(\d) to (?:be )?wound(?: rolls?)?( (?:if|when) strength (?:exceeds|is greater than) toughness)?`)
+	minWoundPattern     = regexp.MustCompile(`(?i)wounded only on (?:an? )?(?:unmodified )?(\d)\+`)
+	transhumanPattern   = regexp.MustCompile(`(?i)^transhuman(?: physiology)?\b`)
+)
+
+// Parse a single defender ability into the modifier set, returning true if it applied
+func (m *DefensiveModifiers) parse(text string) bool {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	applied := false
+
+	if lower == "stealth" {
+		m.RangedHitMod--
+		applied = true
+	} else if matches := hitPenaltyPattern.FindStringSubmatch(lower); matches != nil {
+		value, _ := strconv.Atoi(matches[1])
+		if matches[2] != "ranged" {
+			m.MeleeHitMod -= value
+		}
+		if matches[2] != "melee" {
+			m.RangedHitMod -= value
+		}
+		applied = true
+	}
+
+	if matches := woundPenaltyPattern.FindStringSubmatch(lower); matches != nil {
+		value, _ := strconv.Atoi(matches[1])
+		if matches[2] != "" {
+			m.WoundModStrong -= value
+		} else {
+			m.WoundMod -= value
+		}
+		applied = true
+	}
+
+	if strings.Contains(lower, "cannot be re-rolled") || strings.Contains(lower, "cannot be rerolled") {
+		hitOnly := strings.Contains(lower, "hit roll")
+		woundOnly := strings.Contains(lower, "wound roll")
+		if hitOnly || !woundOnly {
+			m.NoHitRerolls = true
+		}
+		if woundOnly || !hitOnly {
+			m.NoWoundRerolls = true
+		}
+		applied = true
+	}
+
+	if transhumanPattern.MatchString(lower) {
+		m.MinWoundRoll = 4
+		applied = true
+	} else if matches := minWoundPattern.FindStringSubmatch(lower); matches != nil {
+		m.MinWoundRoll, _ = strconv.Atoi(matches[1])
+		applied = true
+	}
+
+	if applied {
+		m.Sources = append(m.Sources, text)
+	}
+	return applied
+}
+
+// Build the defender's hit and wound modifiers from its abilities. They are derived once per
+// matchup alongside the attacker's weapons, see deriveWeaponView.
+func (conflict *UnitAttackSequence) defenderModifiers() DefensiveModifiers {
+	modifiers := DefensiveModifiers{}
+	for _, ability := range conflict.Defender.Abilities {
+		modifiers.parse(ability)
+	}
+	return modifiers
+}
+
+// Hit modifier the defender imposes on a weapon
+func (m DefensiveModifiers) hitMod(weapon WeaponProfile) int {
+	if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+		return m.MeleeHitMod
+	}
+	return m.RangedHitMod
+}
+
+// Wound modifier the defender imposes for a given Strength against its Toughness
+func (m DefensiveModifiers) woundMod(strength, toughness int) int {
+	if strength > toughness {
+		return m.WoundMod + m.WoundModStrong
+	}
+	return m.WoundMod
+}
+
+// Hit and wound rolls can never be modified by more than 1 either way
+const _maxRollModifier = 1
+
+// Cap the combined modifier to a hit or wound roll
+func capRollModifier(modifier int) int {
+	if modifier > _maxRollModifier {
+		return _maxRollModifier
+	}
+	if modifier < -_maxRollModifier {
+		return -_maxRollModifier
+	}
+	return modifier
+}
+
+// Apply the defender's modifiers to a copy of a weapon for a single attack
+func (m DefensiveModifiers) applyToWeapon(weapon WeaponProfile) WeaponProfile {
+	weapon.Modifiers.HitMod += m.hitMod(weapon)
+	if m.NoHitRerolls {
+		weapon.Modifiers.RerollHits = false
+		weapon.Modifiers.RerollHit1s = false
+		weapon.Modifiers.CritHitFish = false
+	}
+	if m.NoWoundRerolls {
+		weapon.Modifiers.RerollWounds = false
+		weapon.Modifiers.RerollWound1s = false
+		weapon.Modifiers.CritWoundFish = false
+	}
+	return weapon
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Abilities that fire when a model is destroyed and must not fire twice for the same model
+var _destructionAbilities = []string{"deadly demise", "fights on death"}
+
+// Swap sides so the defender's models can deal damage back to the attacker. Models are
+// shared with the original sequence, so losses inflicted here persist. The scenario still
+// applies, less the mortal wound actions that belong to the original attacker.
+func (conflict *UnitAttackSequence) reversed() UnitAttackSequence {
+	if conflict.views == nil {
+		conflict.views = &weaponViews{}
+	}
+	if conflict.reverseViews == nil {
+		conflict.reverseViews = &weaponViews{}
+	}
+	return UnitAttackSequence{
+		Attacker:     conflict.Defender,
+		Defender:     conflict.Attacker,
+		Scenario:     conflict.Scenario.reversed(),
+		dice:         conflict.dice,
+		logger:       conflict.logger,
+		views:        conflict.reverseViews,
+		reverseViews: conflict.views,
+	}
+}
+
+// Raised from applyDamage each time a defender model is destroyed
+func (conflict *UnitAttackSequence) onModelDestroyed(modelIndex int, params []string) {
+	model := conflict.Defender.Models[modelIndex]
+
+	if conflict.logger != nil {
+		conflict.logger.Info("Model Destroyed",
+			zap.String("unit", conflict.Defender.Name),
+			zap.String("model", model.Name),
+			zap.Int("models_remaining", model.Count-model.Killed))
+	}
+
+	if exists, value := stringExistsInSlice("Deadly Demise", conflict.Defender.Abilities); exists {
+		conflict.deadlyDemise(model, value)
+	}
+
+	if exists, _ := stringExistsInSlice("melee", params); exists && abilityCheck("Fights on Death", conflict.Defender.Abilities) {
+		conflict.fightsOnDeath(model)
+	}
+}
+
+// Deadly Demise X: roll a D6, on a 6 the attacker suffers X mortal wounds
+func (conflict *UnitAttackSequence) deadlyDemise(model ModelData, value string) {
+	if value == "" {
+		// Library files often drop the value, so assume the smallest and say so
+		value = "1"
+		if conflict.logger != nil {
+			conflict.logger.Warn(fmt.Sprintf("Deadly Demise on %s has no value, assuming Deadly Demise 1", conflict.Defender.Name))
+		}
+	}
+
+	roll := rollDice(conflict.dice, 1, 6)
+	if roll < 6 {
+		if conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("Deadly Demise %s: %s rolled %d, no explosion", value, model.Name, roll))
+		}
+		return
+	}
+
+	mortalWounds, err := rollExpression(conflict.dice, value)
+	if err != nil {
+		if conflict.logger != nil {
+			conflict.logger.Warn(fmt.Sprintf("Could not parse Deadly Demise value '%s': %v", value, err))
+		}
+		return
+	}
+
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Deadly Demise %s: %s rolled %d, %s suffers %d mortal wounds",
+			value,
+			model.Name,
+			roll,
+			conflict.Attacker.Name,
+			mortalWounds))
+	}
+
+	reverse := conflict.reversed()
+	reverse.allocateMortalWounds(mortalWounds, "Deadly Demise")
+}
+
+// Fights on Death: the destroyed model fights with its melee weapons before it is removed
+func (conflict *UnitAttackSequence) fightsOnDeath(model ModelData) {
+	striker := conflict.Defender
+	striker.LoadoutOptions = nil
+
+	// The model has already been destroyed, so its own destruction abilities cannot fire again
+	striker.Abilities = nil
+	for _, ability := range conflict.Defender.Abilities {
+		isDestructionAbility := false
+		for _, destructionAbility := range _destructionAbilities {
+			if strings.HasPrefix(strings.ToLower(ability), destructionAbility) {
+				isDestructionAbility = true
+			}
+		}
+		if !isDestructionAbility {
+			striker.Abilities = append(striker.Abilities, ability)
+		}
+	}
+
+	// Strike back with a single live copy of the model armed only with its melee weapons
+	meleeWeapons := make(map[string]WeaponProfile)
+	for weaponName, weapon := range model.Loadouts {
+		if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+			meleeWeapons[weaponName] = weapon
+		}
+	}
+	model.Count = 1
+	model.Killed = 0
+	model.CarryOverWounds = 0
+	model.Loadouts = meleeWeapons
+	striker.Models = []ModelData{model}
+
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Fights on Death: %s strikes back at %s before removal", model.Name, conflict.Attacker.Name))
+	}
+
+	fight := UnitAttackSequence{
+		Attacker: striker,
+		Defender: conflict.Attacker,
+		Scenario: conflict.Scenario.reversed(),
+		dice:     conflict.dice,
+		logger:   conflict.logger,
+	}
+	_, damage := fight.loadoutAttackSequence()
+
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Fights on Death Complete: %s dealt %d damage to %s", model.Name, damage, conflict.Attacker.Name))
+	}
+}
+
+// Total models destroyed across all model groups
+func (u *Unit) ModelsLost() int {
+	lost := 0
+	for _, model := range u.Models {
+		lost += model.Killed
+	}
+	return lost
+}
+
+// Total wounds lost, counting destroyed models and damage on the surviving model
+func (u *Unit) WoundsLost() int {
+	lost := 0
+	for _, model := range u.Models {
+		lost += model.Killed * model.Wounds
+		if model.Killed < model.Count {
+			lost += model.CarryOverWounds
+		}
+	}
+	return lost
+}
+
+// Total wounds across every model in the unit at full strength
+func (u *Unit) TotalWounds() int {
+	total := 0
+	for _, model := range u.Models {
+		total += model.Count * model.Wounds
+	}
+	return total
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Most model group weapons the firing order search will permute
+const _maxWeaponOrderSearch = 6
+
+// Weapons a model group fires by default. The first group loadout option picks them for every
+// model group where the unit has one, as a model group's loadouts list alternative wargear
+// that cannot all be carried; otherwise every weapon fires, in name order so runs never depend
+// on map iteration.
+func (u *Unit) firingOrder(model ModelData) []string {
+	weapons := []string{}
+	for _, option := range u.LoadoutOptions {
+		if option.Type != "group" {
+			continue
+		}
+		for _, name := range option.Options {
+			if _, exists := model.Loadouts[name]; exists {
+				weapons = append(weapons, name)
+			}
+		}
+		return weapons
+	}
+
+	for name := range model.Loadouts {
+		weapons = append(weapons, name)
+	}
+	sort.Strings(weapons)
+	return weapons
+}
+
+// FiringStep is one model group firing one of its weapons
+type FiringStep struct {
+	Group  int
+	Weapon string
+}
+
+// Label of a firing step as written in a firing_order, naming the model group when there
+// is more than one
+func (u *Unit) stepLabel(step FiringStep) string {
+	if len(u.Models) == 1 {
+		return step.Weapon
+	}
+	return fmt.Sprintf("%s: %s", u.Models[step.Group].Name, step.Weapon)
+}
+
+// Labels of the firing steps, in order
+func (u *Unit) stepLabels(steps []FiringStep) []string {
+	labels := make([]string, len(steps))
+	for i, step := range steps {
+		labels[i] = u.stepLabel(step)
+	}
+	return labels
+}
+
+// Every model group and weapon pair the unit fires, in order across the whole unit. An explicit
+// firing_order wins, where an entry is either "Model: Weapon" or a weapon fired by every model
+// group that carries it; otherwise each model group fires its default weapons in turn. A pair
+// named by more than one entry fires once, at its first entry.
+func (u *Unit) firingSteps() []FiringStep {
+	steps := []FiringStep{}
+	if len(u.FiringOrder) == 0 {
+		for i, model := range u.Models {
+			for _, weapon := range u.firingOrder(model) {
+				steps = append(steps, FiringStep{Group: i, Weapon: weapon})
+			}
+		}
+		return steps
+	}
+
+	seen := map[FiringStep]bool{}
+	for _, entry := range u.FiringOrder {
+		for i, model := range u.Models {
+			step := FiringStep{Group: i, Weapon: entry}
+			if name, weapon, found := strings.Cut(entry, ":"); found && strings.TrimSpace(name) == model.Name {
+				step.Weapon = strings.TrimSpace(weapon)
+			}
+			if _, exists := model.Loadouts[step.Weapon]; exists && !seen[step] {
+				seen[step] = true
+				steps = append(steps, step)
+			}
+		}
+	}
+	return steps
+}
+
+// Every ordering of the firing steps
+func stepPermutations(steps []FiringStep) [][]FiringStep {
+	if len(steps) <= 1 {
+		return [][]FiringStep{steps}
+	}
+	orders := [][]FiringStep{}
+	for i := range steps {
+		rest := append(append([]FiringStep{}, steps[:i]...), steps[i+1:]...)
+		for _, tail := range stepPermutations(rest) {
+			orders = append(orders, append([]FiringStep{steps[i]}, tail...))
+		}
+	}
+	return orders
+}
+
+// WeaponOrderSummary is the outcome of one firing order against a defender
+type WeaponOrderSummary struct {
+	Order   []string
+	Summary MatchupSummary
+}
+
+// Ranking of firing orders: the most mean models killed, then the least mean overkill damage
+func betterFiringOrder(left, right MatchupSummary) bool {
+	if left.ModelsKilled != right.ModelsKilled {
+		return left.ModelsKilled > right.ModelsKilled
+	}
+	return left.MeanOverkill < right.MeanOverkill
+}
+
+// Try every order of each attacker's model group and weapon pairs against each defender on the
+// same dice, so one group's weapon can fire ahead of another's, and rank them by betterFiringOrder
+func runWeaponOrders(attackerSpec, defenderSpec string, scenario Scenario, workers int, seed int64) error {
+	attackers, err := selectUnits(attackerSpec)
+	if err != nil {
+		return err
+	}
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	attackerLabels := matrixLabels(attackers)
+	defenderLabels := matrixLabels(defenders)
+
+	for a, attackerFile := range attackers {
+		base := loadUnit(attackerFile)
+		defaultSteps := base.firingSteps()
+		if len(defaultSteps) > _maxWeaponOrderSearch {
+			return fmt.Errorf("%s fires %d model group weapons, the order search is limited to %d", attackerLabels[a], len(defaultSteps), _maxWeaponOrderSearch)
+		}
+
+		for d, defenderFile := range defenders {
+			fmt.Printf("=== Weapon firing order: %s against %s ===\n", attackerLabels[a], defenderLabels[d])
+			fmt.Printf("Ranked by mean models killed, then by least mean overkill damage\n")
+			summaries := []WeaponOrderSummary{}
+			for _, steps := range stepPermutations(defaultSteps) {
+				attacker := base.Clone()
+				attacker.FiringOrder = base.stepLabels(steps)
+				conflict := UnitAttackSequence{
+					Attacker: attacker,
+					Defender: loadUnit(defenderFile),
+					Scenario: scenario,
+				}
+				results := runSimulations(conflict, _numSimulations, workers, seed, nil)
+				summaries = append(summaries, WeaponOrderSummary{attacker.FiringOrder, summariseMatchup(results, conflict.Attacker, conflict.Defender)})
+			}
+			defaultSummary := summaries[0] // The first permutation is the default order
+
+			sort.SliceStable(summaries, func(i, j int) bool {
+				return betterFiringOrder(summaries[i].Summary, summaries[j].Summary)
+			})
+
+			fmt.Printf("%13s  %8s  %9s  %s\n", "Models killed", "Overkill", "Destroyed", "Order")
+			for _, summary := range summaries {
+				fmt.Printf("%13.2f  %8.2f  %8.1f%%  %s\n",
+					summary.Summary.ModelsKilled,
+					summary.Summary.MeanOverkill,
+					100*summary.Summary.KillChance,
+					strings.Join(summary.Order, " -> "))
+			}
+			fmt.Printf("Best order: %s\n", strings.Join(summaries[0].Order, " -> "))
+			fmt.Printf("Default order: %s (%.2f models killed, %.2f overkill)\n\n",
+				strings.Join(defaultSummary.Order, " -> "),
+				defaultSummary.Summary.ModelsKilled,
+				defaultSummary.Summary.MeanOverkill)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFiringSteps(t *testing.T) {
+	weapons := map[string]WeaponProfile{"Bolt Pistol": {}, "Power Sword": {}, "Plasma Gun": {}}
+	models := []ModelData{
+		{Name: "Sergeant", Loadouts: weapons},
+		{Name: "Marine", Loadouts: map[string]WeaponProfile{"Bolt Pistol": {}}},
+	}
+	group := []LoadoutOption{{Name: "Wargear", Type: "group", Options: []string{"Power Sword", "Bolt Pistol"}}}
+
+	tests := []struct {
+		name string
+		unit Unit
+		want []FiringStep
+	}{
+		{"every weapon in name order", Unit{Models: models}, []FiringStep{
+			{0, "Bolt Pistol"}, {0, "Plasma Gun"}, {0, "Power Sword"}, {1, "Bolt Pistol"},
+		}},
+		{"group option picks and orders weapons", Unit{Models: models, LoadoutOptions: group}, []FiringStep{
+			{0, "Power Sword"}, {0, "Bolt Pistol"}, {1, "Bolt Pistol"},
+		}},
+		{"group option naming no weapons fires nothing", Unit{Models: models, LoadoutOptions: []LoadoutOption{
+			{Name: "Wargear", Type: "group", Options: []string{"Pistol and Melee Weapon"}},
+		}}, []FiringStep{}},
+		{"firing order interleaves model groups", Unit{Models: models, FiringOrder: []string{
+			"Marine: Bolt Pistol", "Sergeant: Plasma Gun", "Bolt Pistol",
+		}}, []FiringStep{
+			{1, "Bolt Pistol"}, {0, "Plasma Gun"}, {0, "Bolt Pistol"},
+		}},
+		{"repeated entries fire once", Unit{Models: models, FiringOrder: []string{
+			"Bolt Pistol", "Sergeant: Bolt Pistol", "Plasma Gun", "Plasma Gun",
+		}}, []FiringStep{
+			{0, "Bolt Pistol"}, {1, "Bolt Pistol"}, {0, "Plasma Gun"},
+		}},
+	}
+	for _, test := range tests {
+		if got := test.unit.firingSteps(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
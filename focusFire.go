@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Longest firing sequence the order search will permute
+const _maxFocusOrderSearch = 6
+
+// FocusAttacker is one unit in a focus fire sequence, optionally firing a single weapon
+type FocusAttacker struct {
+	Label  string
+	Unit   Unit
+	Weapon string // Only this weapon fires when set
+}
+
+// FocusResult is the outcome of one simulated focus fire sequence
+type FocusResult struct {
+	Needed      []bool // Target was still alive when each unit's turn came
+	Damage      []int  // Damage dealt by each unit
+	Destroyed   bool
+	DestroyedBy int // Index of the unit that finished the target, -1 if it survived
+	Overkill    int // Damage wasted beyond the wounds each target model had left
+}
+
+// Parse a firing sequence such as "vindicator.yaml,captain.yaml:Heavy Bolter" into
+// attackers, each optionally limited to one of its weapons
+func parseFocusSequence(spec string) ([]FocusAttacker, error) {
+	attackers := []FocusAttacker{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		file, weapon, _ := strings.Cut(entry, ":")
+		file = strings.TrimSpace(file)
+		weapon = strings.TrimSpace(weapon)
+
+		unit := loadUnit(file)
+		label := unit.Name
+		if weapon != "" {
+			label = fmt.Sprintf("%s (%s)", unit.Name, weapon)
+			restricted := false
+			for i, model := range unit.Models {
+				loadouts := map[string]WeaponProfile{}
+				if profile, exists := model.Loadouts[weapon]; exists {
+					loadouts[weapon] = profile
+					restricted = true
+				}
+				unit.Models[i].Loadouts = loadouts
+			}
+			if !restricted {
+				return nil, fmt.Errorf("%s has no weapon '%s'", unit.Name, weapon)
+			}
+			unit.FiringOrder = []string{weapon}
+		}
+		attackers = append(attackers, FocusAttacker{Label: label, Unit: unit, Weapon: weapon})
+	}
+	if len(attackers) == 0 {
+		return nil, fmt.Errorf("no attackers in focus fire sequence '%s'", spec)
+	}
+	return attackers, nil
+}
+
+// Fire each attacker at the defender in order, carrying wound state between them and
+// stopping once the defender is destroyed. views holds each attacker's derived weapons
+// and the defender's weapons against it, so they are derived once per worker.
+func focusFire(attackers []Unit, views [][2]*weaponViews, defender *Unit, scenario Scenario, dice DiceSource) FocusResult {
+	result := FocusResult{
+		Needed:      make([]bool, len(attackers)),
+		Damage:      make([]int, len(attackers)),
+		DestroyedBy: -1,
+	}
+
+	for i := range attackers {
+		conflict := UnitAttackSequence{
+			Attacker: attackers[i],
+			Defender: *defender,
+			Scenario: scenario,
+			dice:     dice,
+
+			views:        views[i][0],
+			reverseViews: views[i][1],
+		}
+		if conflict.allocationTarget() < 0 {
+			break
+		}
+		result.Needed[i] = true
+
+		_, result.Damage[i] = conflict.simulate()
+		result.Overkill += conflict.tally.Overkill
+		*defender = conflict.Defender
+
+		if conflict.allocationTarget() < 0 {
+			result.Destroyed = true
+			result.DestroyedBy = i
+			break
+		}
+	}
+	return result
+}
+
+// Simulate a firing order against a defender, returning results in simulation order
+func runFocusSimulations(order []FocusAttacker, defenderTemplate Unit, scenario Scenario, count, workers int, seed int64) []FocusResult {
+	results := make([]FocusResult, count)
+	runWorkers(count, workers, func() func(int) {
+		attackers := make([]Unit, len(order))
+		views := make([][2]*weaponViews, len(order))
+		for i, attacker := range order {
+			attackers[i] = attacker.Unit.Clone()
+			views[i] = [2]*weaponViews{{}, {}}
+		}
+		defender := defenderTemplate.Clone()
+
+		return func(index int) {
+			for i := range attackers {
+				attackers[i].Reset()
+			}
+			defender.Reset()
+			results[index] = focusFire(attackers, views, &defender, scenario, newDiceSource(seed, index))
+		}
+	})
+	return results
+}
+
+// FocusSummary holds the headline numbers of a firing order
+type FocusSummary struct {
+	Order        []FocusAttacker
+	Destroyed    float64
+	MeanOverkill float64
+	Needed       []float64 // Chance each unit is needed, i.e. the target is alive at its turn
+	Finishes     []float64 // Chance each unit destroys the target
+	MeanDamage   []float64
+}
+
+func summariseFocus(order []FocusAttacker, results []FocusResult) FocusSummary {
+	summary := FocusSummary{
+		Order:      order,
+		Needed:     make([]float64, len(order)),
+		Finishes:   make([]float64, len(order)),
+		MeanDamage: make([]float64, len(order)),
+	}
+	n := float64(len(results))
+	for _, result := range results {
+		if result.Destroyed {
+			summary.Destroyed++
+			summary.Finishes[result.DestroyedBy]++
+		}
+		summary.MeanOverkill += float64(result.Overkill)
+		for i := range order {
+			if result.Needed[i] {
+				summary.Needed[i]++
+			}
+			summary.MeanDamage[i] += float64(result.Damage[i])
+		}
+	}
+	summary.Destroyed /= n
+	summary.MeanOverkill /= n
+	for i := range order {
+		summary.Needed[i] /= n
+		summary.Finishes[i] /= n
+		summary.MeanDamage[i] /= n
+	}
+	return summary
+}
+
+func (summary FocusSummary) print() {
+	fmt.Printf("Destroyed: %.1f%%, wasted overkill damage: %.2f\n", 100*summary.Destroyed, summary.MeanOverkill)
+	fmt.Printf("%-40s  %7s  %11s  %8s\n", "Unit", "Needed", "Mean damage", "Finishes")
+	for i, attacker := range summary.Order {
+		fmt.Printf("%-40s  %6.1f%%  %11.2f  %7.1f%%\n",
+			attacker.Label,
+			100*summary.Needed[i],
+			summary.MeanDamage[i],
+			100*summary.Finishes[i])
+	}
+}
+
+// Labels of a firing order joined with arrows
+func focusOrderLabel(order []FocusAttacker) string {
+	labels := []string{}
+	for _, attacker := range order {
+		labels = append(labels, attacker.Label)
+	}
+	return strings.Join(labels, " -> ")
+}
+
+// Every ordering of the attackers
+func focusPermutations(attackers []FocusAttacker) [][]FocusAttacker {
+	if len(attackers) <= 1 {
+		return [][]FocusAttacker{attackers}
+	}
+	orders := [][]FocusAttacker{}
+	for i := range attackers {
+		rest := append(append([]FocusAttacker{}, attackers[:i]...), attackers[i+1:]...)
+		for _, tail := range focusPermutations(rest) {
+			orders = append(orders, append([]FocusAttacker{attackers[i]}, tail...))
+		}
+	}
+	return orders
+}
+
+// Ranking of focus fire orders: the highest chance to destroy the target, then the least mean
+// overkill damage, so an order never wins by killing the target less often
+func betterFocusOrder(left, right FocusSummary) bool {
+	if left.Destroyed != right.Destroyed {
+		return left.Destroyed > right.Destroyed
+	}
+	return left.MeanOverkill < right.MeanOverkill
+}
+
+// Run a focus fire sequence against each defender, optionally searching every firing order
+// for the best one by betterFocusOrder
+func runFocusFire(sequenceSpec, defenderSpec string, searchOrders bool, scenario Scenario, workers int, seed int64) error {
+	sequence, err := parseFocusSequence(sequenceSpec)
+	if err != nil {
+		return err
+	}
+	if searchOrders && len(sequence) > _maxFocusOrderSearch {
+		return fmt.Errorf("order search is limited to %d units, got %d", _maxFocusOrderSearch, len(sequence))
+	}
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	defenderLabels := matrixLabels(defenders)
+
+	for d, defenderFile := range defenders {
+		defender := loadUnit(defenderFile)
+		fmt.Printf("=== Focus fire on %s: %s ===\n", defenderLabels[d], focusOrderLabel(sequence))
+		summariseFocus(sequence, runFocusSimulations(sequence, defender, scenario, _numSimulations, workers, seed)).print()
+
+		if searchOrders && len(sequence) > 1 {
+			// Every order rolls the same dice streams, so differences come from the order alone
+			summaries := []FocusSummary{}
+			for _, order := range focusPermutations(sequence) {
+				results := runFocusSimulations(order, defender, scenario, _numSimulations, workers, seed)
+				summaries = append(summaries, summariseFocus(order, results))
+			}
+			sort.SliceStable(summaries, func(i, j int) bool {
+				return betterFocusOrder(summaries[i], summaries[j])
+			})
+
+			fmt.Printf("--- Firing orders by chance to destroy, then by wasted damage ---\n")
+			for _, summary := range summaries {
+				fmt.Printf("%5.1f%% destroyed  %6.2f wasted  %s\n", 100*summary.Destroyed, summary.MeanOverkill, focusOrderLabel(summary.Order))
+			}
+			fmt.Printf("Best order: %s\n", focusOrderLabel(summaries[0].Order))
+			summaries[0].print()
+		}
+		fmt.Printf("\n")
+	}
+	return nil
+}
@@ -2,15 +2,139 @@ package main
 
 import (
 	"encoding/csv"
+	"flag"
 	"fmt"
-	"math/rand"
 	"os"
-	"sort"
+	"runtime"
 	"time"
 )
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	scenarioFile := flag.String("scenario", "", "Scenario YAML file in ./scenarios/ (e.g. psychic_barrage.yaml)")
+	workers := flag.Int("workers", runtime.NumCPU(), "Number of simulations to run concurrently")
+	seed := flag.Int64("seed", 0, "Random seed for the run, picked from the clock when not given")
+	replay := flag.Int("replay", 0, "Replay only simulation N of the run given by -seed (as numbered in the CSV) into the combat log")
+	quantileList := flag.String("quantiles", _defaultQuantiles, "Comma separated damage quantiles to report, between 0 and 1")
+	matrix := flag.Bool("matrix", false, "Run every pairing of -attackers and -defenders and write a CSV grid and HTML heatmap")
+	attackerSpec := flag.String("attackers", "*.yaml", "Matrix, sweep and breakpoint attackers: comma separated library globs or tags, e.g. \"captain*.yaml,tag:Vehicle\"")
+	defenderSpec := flag.String("defenders", "*.yaml", "Matrix, breakpoint and focus fire defenders: comma separated library globs, tags or reference targets (ref:meq, ref:*)")
+	metricList := flag.String("metrics", _defaultMatrixMetrics, "Matrix metrics: mean_damage, kill_chance, models_killed, overkill, damage_per_100, points_per_point, roi")
+	sweepSpec := flag.String("sweep", "", "Sweep defender characteristics for each of -attackers, e.g. \"T=3-12;SV=2-6\" (axes T, SV, ISV, W, FNP, MODELS)")
+	sweepBase := flag.String("sweep-base", "ref:meq", "Defender the sweep starts from: a reference target such as ref:teq, or a library file")
+	breakpointMode := flag.String("breakpoint", "", "Find how many copies or legal models of each of -attackers destroy each of -defenders: \"copies\" or \"models\"")
+	confidence := flag.Float64("confidence", 0.8, "Breakpoint chance to destroy the defender, between 0 and 1")
+	breakpointLimit := flag.Int("max-steps", 20, "Most copies the breakpoint finder tries")
+	focusSpec := flag.String("focus", "", "Focus fire: attackers shooting each of -defenders in order, e.g. \"vindicator.yaml,captain.yaml:Heavy Bolter\"")
+	searchOrders := flag.Bool("focus-orders", false, "Also try every focus fire order and report the one most likely to destroy the target, wasting the least damage")
+	weaponOrders := flag.Bool("weapon-order", false, "Try every order each of -attackers can fire its model group weapons in against each of -defenders and rank them by models killed, then overkill")
+	splitSpec := flag.String("split", "", "Split fire: library unit whose models and weapons are divided across -defenders (up to 4 targets and 256 weapon subsets), e.g. vindicator.yaml")
+	splitPriority := flag.String("split-priority", "", "Split fire priority target among -defenders whose chance to be destroyed is maximised, instead of total points destroyed")
+	sensitivity := flag.Bool("sensitivity", false, "Rerun each of -attackers against each of -defenders with single buffs such as +1 to hit and report the change each makes")
+	conformanceDir := flag.String("conformance", "", "Run the rules conformance cases in a directory (e.g. conformance) and exit")
+	flag.Parse()
+
+	quantiles, err := parseQuantiles(*quantileList)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	if *conformanceDir != "" {
+		if runConformance(*conformanceDir) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Any seed, including 0, can be given; only a missing one is picked from the clock
+	seedSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if *replay != 0 && !seedSet {
+		fmt.Println("-replay needs the -seed of the run to reproduce")
+		os.Exit(2)
+	}
+	if *replay < 0 || *replay > _numSimulations {
+		fmt.Printf("-replay must be a simulation between 1 and %d\n", _numSimulations)
+		os.Exit(2)
+	}
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("Seed: %d\n", *seed)
+
+	var scenario Scenario
+	if *scenarioFile != "" {
+		scenario = loadScenario(*scenarioFile)
+		fmt.Printf("Using scenario: %s\n", scenario.Name)
+	}
+
+	if *focusSpec != "" {
+		if err := runFocusFire(*focusSpec, *defenderSpec, *searchOrders, scenario, *workers, *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *sensitivity {
+		if err := runSensitivity(*attackerSpec, *defenderSpec, scenario, *workers, *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *splitSpec != "" {
+		if err := runSplitFire(*splitSpec, *defenderSpec, *splitPriority, scenario, *workers, *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *weaponOrders {
+		if err := runWeaponOrders(*attackerSpec, *defenderSpec, scenario, *workers, *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *breakpointMode != "" {
+		if err := runBreakpoints(*attackerSpec, *defenderSpec, *breakpointMode, *confidence, *breakpointLimit, scenario, *workers, *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *sweepSpec != "" {
+		axes, err := parseSweep(*sweepSpec)
+		if err == nil {
+			err = runSweep(*attackerSpec, *sweepBase, axes, scenario, *workers, *seed)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *matrix {
+		metrics, err := parseMatrixMetrics(*metricList)
+		if err == nil {
+			err = runMatrix(*attackerSpec, *defenderSpec, metrics, scenario, *workers, *seed)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	attackerFiles := []struct {
 		name string
@@ -29,19 +153,36 @@ func main() {
 	}
 
 	for _, att := range attackerFiles {
-		var conflict UnitAttackSequence
-		conflict.Attacker = loadUnit(att.file)
-
 		for _, def := range defenderFiles {
 			fmt.Printf("=== Testing %s against %s ===\n", att.name, def.name)
-			conflict.Defender = loadUnit(def.file)
 
-			// Run simulations for statistical analysis
-			damages := []int{}
+			// Each worker clones the loaded units and derives its own weapons from them
+			conflict := UnitAttackSequence{
+				Attacker: loadUnit(att.file),
+				Defender: loadUnit(def.file),
+				Scenario: scenario,
+			}
 
-			// Initialize logger only for the first simulation
-			initLogger()
-			defer closeLogger()
+			// Only the first simulation is written to the combat log
+			logger := newCombatLogger()
+
+			if *replay > 0 {
+				result := replaySimulation(conflict, *replay-1, *seed, logger)
+				fmt.Printf("--- Replay of simulation %d ---\n", *replay)
+				fmt.Printf("Total damage: %d\n", result.TotalDamage)
+				for _, weaponName := range conflict.damageColumns() {
+					fmt.Printf("  %s: %d\n", weaponName, result.DamageByLoadout[weaponName])
+				}
+				fmt.Printf("Attacker losses: %d wounds, %d models\n", result.AttackerWoundsLost, result.AttackerModelsLost)
+				fmt.Printf("Battle-shocked: attacker %t, defender %t\n", result.AttackerBattleShocked, result.DefenderBattleShocked)
+				fmt.Printf("Objective Control: attacker %d, defender %d\n", result.AttackerOC, result.DefenderOC)
+				fmt.Printf("\n")
+				logger.Sync()
+				continue
+			}
+
+			results := runSimulations(conflict, _numSimulations, *workers, *seed, logger)
+			logger.Sync()
 
 			// Create CSV file for simulation results
 			csvFile, err := os.Create(fmt.Sprintf("simulation_results_%s_vs_%s.csv", att.name, def.name))
@@ -55,53 +196,155 @@ func main() {
 			defer writer.Flush()
 
 			// Write header
-			header := []string{"Simulation", "Total Damage"}
-			// Add weapon columns based on first simulation
-			damageByLoadout, _ := conflict.loadoutAttackSequence()
-			for weaponName := range damageByLoadout {
-				header = append(header, weaponName)
+			header := []string{"Simulation", "Total Damage", "Attacker Wounds Lost", "Attacker Models Lost", "Attacker Battle-shocked", "Defender Battle-shocked", "Attacker OC", "Defender OC"}
+			header = append(header, "Defender Wounds Lost", "Defender Models Lost", "Defender Destroyed", "Overkill", "Damage Ignored",
+				"Defender Points Destroyed", "Defender Model Points Destroyed", "Attacker Points Lost", "Damage per 100 Points", "Return on Investment")
+			for _, model := range conflict.Defender.Models {
+				header = append(header, "Killed: "+model.Name)
 			}
-			writer.Write(header)
+			weaponColumns := conflict.damageColumns()
+			writer.Write(append(header, weaponColumns...))
 
-			for i := 0; i < _numSimulations; i++ {
-				if i > 0 {
-					// Disable detailed combat logging after first simulation
-					combatLogger = nil
+			// Merge results in simulation order
+			damages := []int{}
+			attackerWoundsLost := 0
+			attackerModelsLost := 0
+			attackerBattleShocked := 0
+			defenderBattleShocked := 0
+			attackerOC := 0
+			defenderOC := 0
+			objectivesFlipped := 0
+			modelsLost := []int{}
+			defenderWoundsLost := 0
+			defenderDestroyed := 0
+			overkill := 0
+			damageIgnored := 0
+			defenderPointsLost := 0.0
+			defenderModelPointsLost := 0.0
+			returnOnInvestment := 0.0
+			killedByModel := make([]int, len(conflict.Defender.Models))
+			wipedByModel := make([]int, len(conflict.Defender.Models))
+			for i, result := range results {
+				damages = append(damages, result.TotalDamage)
+				modelsLost = append(modelsLost, result.DefenderModelsLost)
+				defenderWoundsLost += result.DefenderWoundsLost
+				overkill += result.Overkill
+				damageIgnored += result.DamageIgnored
+				defenderPointsLost += result.DefenderPointsLost
+				defenderModelPointsLost += result.DefenderModelPointsLost
+				returnOnInvestment += result.ReturnOnInvestment(conflict.Attacker.Cost)
+				if result.DefenderDestroyed {
+					defenderDestroyed++
+				}
+				for m, killed := range result.ModelsKilled {
+					killedByModel[m] += killed
+					if killed >= conflict.Defender.Models[m].Count {
+						wipedByModel[m]++
+					}
+				}
+				attackerWoundsLost += result.AttackerWoundsLost
+				attackerModelsLost += result.AttackerModelsLost
+				if result.AttackerBattleShocked {
+					attackerBattleShocked++
+				}
+				if result.DefenderBattleShocked {
+					defenderBattleShocked++
+				}
+				attackerOC += result.AttackerOC
+				defenderOC += result.DefenderOC
+				if result.AttackerOC > result.DefenderOC {
+					objectivesFlipped++
 				}
-
-				damageByLoadout, totalDamage := conflict.loadoutAttackSequence()
-				damages = append(damages, totalDamage)
 
 				// Write simulation result to CSV
 				row := []string{
 					fmt.Sprintf("%d", i+1),
-					fmt.Sprintf("%d", totalDamage),
+					fmt.Sprintf("%d", result.TotalDamage),
+					fmt.Sprintf("%d", result.AttackerWoundsLost),
+					fmt.Sprintf("%d", result.AttackerModelsLost),
+					fmt.Sprintf("%t", result.AttackerBattleShocked),
+					fmt.Sprintf("%t", result.DefenderBattleShocked),
+					fmt.Sprintf("%d", result.AttackerOC),
+					fmt.Sprintf("%d", result.DefenderOC),
+					fmt.Sprintf("%d", result.DefenderWoundsLost),
+					fmt.Sprintf("%d", result.DefenderModelsLost),
+					fmt.Sprintf("%t", result.DefenderDestroyed),
+					fmt.Sprintf("%d", result.Overkill),
+					fmt.Sprintf("%d", result.DamageIgnored),
+					fmt.Sprintf("%.1f", result.DefenderPointsLost),
+					fmt.Sprintf("%.1f", result.DefenderModelPointsLost),
+					fmt.Sprintf("%.1f", result.AttackerPointsLost),
+					fmt.Sprintf("%.2f", damagePer100Points(float64(result.TotalDamage), conflict.Attacker.Cost)),
+					fmt.Sprintf("%.3f", result.ReturnOnInvestment(conflict.Attacker.Cost)),
+				}
+				for _, killed := range result.ModelsKilled {
+					row = append(row, fmt.Sprintf("%d", killed))
 				}
 				// Add weapon damage values
-				for _, weaponName := range header[2:] {
-					row = append(row, fmt.Sprintf("%d", damageByLoadout[weaponName]))
+				for _, weaponName := range weaponColumns {
+					row = append(row, fmt.Sprintf("%d", result.DamageByLoadout[weaponName]))
 				}
 				writer.Write(row)
-
-				// Reload units for next simulation
-				conflict.Attacker.Reload()
-				conflict.Defender.Reload()
 			}
 
 			// Calculate statistics
-			sort.Slice(damages, func(i, j int) bool {
-				return damages[i] > damages[j]
-			})
-			mean := float64(0)
-			for _, d := range damages {
-				mean += float64(d)
-			}
-			mean /= float64(len(damages))
+			distribution := newDistribution(damages)
+			totalWounds := conflict.Defender.TotalWounds()
 
 			fmt.Printf("--- Statistical Analysis (%d simulations) ---\n", _numSimulations)
-			fmt.Printf("Mean damage: %.2f\n", mean)
-			fmt.Printf("68th percentile: %d\n", damages[int(float64(_numSimulations)*0.32)]) // ~1 standard deviation for normal distribution
-			fmt.Printf("95th percentile: %d\n", damages[int(float64(_numSimulations)*0.05)]) // ~2 standard deviations for normal distribution
+			distribution.printSummary("damage", quantiles)
+			distribution.printTable("Damage", totalWounds)
+			if err := distribution.writeCSV(fmt.Sprintf("damage_distribution_%s_vs_%s.csv", att.name, def.name), "Damage", totalWounds); err != nil {
+				fmt.Printf("Error writing damage distribution: %v\n", err)
+			}
+
+			// Defender losses count only wounds actually removed, not damage lost to overkill or Feel No Pain
+			startingModels := conflict.Defender.startingStrength()
+			killed := newDistribution(modelsLost)
+			fmt.Printf("--- Defender Losses ---\n")
+			fmt.Printf("Unit destroyed: %.1f%%\n", 100*float64(defenderDestroyed)/float64(_numSimulations))
+			fmt.Printf("Wounds removed: %.2f of %d (overkill wasted %.2f, ignored %.2f)\n",
+				float64(defenderWoundsLost)/float64(_numSimulations),
+				totalWounds,
+				float64(overkill)/float64(_numSimulations),
+				float64(damageIgnored)/float64(_numSimulations))
+			fmt.Printf("Models killed: %.2f of %d\n", killed.Mean, startingModels)
+			for m, model := range conflict.Defender.Models {
+				fmt.Printf("  %s: %.2f of %d, all destroyed %.1f%%\n",
+					model.Name,
+					float64(killedByModel[m])/float64(_numSimulations),
+					model.Count,
+					100*float64(wipedByModel[m])/float64(_numSimulations))
+			}
+			killed.printTable("Models", startingModels)
+			if err := killed.writeCSV(fmt.Sprintf("models_killed_%s_vs_%s.csv", att.name, def.name), "Models Killed", startingModels); err != nil {
+				fmt.Printf("Error writing models killed distribution: %v\n", err)
+			}
+
+			// Points efficiency, for choosing between units at similar points
+			if cost := conflict.Attacker.Cost; cost > 0 {
+				fmt.Printf("--- Points Efficiency (%d pts vs %d pts) ---\n", cost, conflict.Defender.Cost)
+				fmt.Printf("Damage per 100 points: %.2f\n", damagePer100Points(distribution.Mean, cost))
+				fmt.Printf("Points destroyed: %.1f proportional, %.1f whole models\n",
+					defenderPointsLost/float64(_numSimulations),
+					defenderModelPointsLost/float64(_numSimulations))
+				fmt.Printf("Points destroyed per point: %.3f proportional, %.3f whole models\n",
+					defenderPointsLost/float64(_numSimulations)/float64(cost),
+					defenderModelPointsLost/float64(_numSimulations)/float64(cost))
+				fmt.Printf("Return on investment: %.3f net points per point spent\n", returnOnInvestment/float64(_numSimulations))
+			} else {
+				fmt.Printf("No points cost for %s, skipping points efficiency\n", conflict.Attacker.Name)
+			}
+			fmt.Printf("Attacker losses: %.2f wounds, %.2f models\n",
+				float64(attackerWoundsLost)/float64(_numSimulations),
+				float64(attackerModelsLost)/float64(_numSimulations))
+			fmt.Printf("Battle-shocked: attacker %.1f%%, defender %.1f%%\n",
+				100*float64(attackerBattleShocked)/float64(_numSimulations),
+				100*float64(defenderBattleShocked)/float64(_numSimulations))
+			fmt.Printf("Objective Control: attacker %.2f, defender %.2f, attacker controls contested objective %.1f%%\n",
+				float64(attackerOC)/float64(_numSimulations),
+				float64(defenderOC)/float64(_numSimulations),
+				100*float64(objectivesFlipped)/float64(_numSimulations))
 			fmt.Printf("\n")
 		}
 	}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Metrics reported when none are given with -metrics
+const _defaultMatrixMetrics = "mean_damage,kill_chance,damage_per_100"
+
+// MatchupSummary holds the headline numbers of one attacker/defender pairing
+type MatchupSummary struct {
+	MeanDamage         float64
+	KillChance         float64 // Chance the whole defending unit is destroyed
+	ModelsKilled       float64
+	WoundsLost         float64 // Mean wounds removed from the defender
+	MeanOverkill       float64 // Damage wasted beyond the wounds each model had left
+	DamagePer100       float64
+	PointsLost         float64 // Mean defender points destroyed, proportional
+	PointsPerPoint     float64 // Defender points destroyed per attacker point, proportional
+	ReturnOnInvestment float64
+}
+
+// Summarise a matchup's simulation results
+func summariseMatchup(results []SimulationResult, attacker, defender Unit) MatchupSummary {
+	summary := MatchupSummary{}
+	if len(results) == 0 {
+		return summary
+	}
+
+	pointsLost := 0.0
+	for _, result := range results {
+		summary.MeanDamage += float64(result.TotalDamage)
+		summary.ModelsKilled += float64(result.DefenderModelsLost)
+		summary.WoundsLost += float64(result.DefenderWoundsLost)
+		summary.MeanOverkill += float64(result.Overkill)
+		if result.DefenderDestroyed {
+			summary.KillChance++
+		}
+		pointsLost += result.DefenderPointsLost
+		summary.ReturnOnInvestment += result.ReturnOnInvestment(attacker.Cost)
+	}
+	n := float64(len(results))
+	summary.MeanDamage /= n
+	summary.ModelsKilled /= n
+	summary.WoundsLost /= n
+	summary.MeanOverkill /= n
+	summary.KillChance /= n
+	summary.ReturnOnInvestment /= n
+	summary.PointsLost = pointsLost / n
+	summary.DamagePer100 = damagePer100Points(summary.MeanDamage, attacker.Cost)
+	if attacker.Cost > 0 {
+		summary.PointsPerPoint = summary.PointsLost / float64(attacker.Cost)
+	}
+	return summary
+}
+
+// MatrixMetric is a number that can be read off a matchup summary and shown in the grid
+type MatrixMetric struct {
+	Key    string
+	Label  string
+	Format string
+	Value  func(MatchupSummary) float64
+}
+
+var _matrixMetrics = []MatrixMetric{
+	{"mean_damage", "Mean damage", "%.2f", func(s MatchupSummary) float64 { return s.MeanDamage }},
+	{"kill_chance", "Chance to destroy the unit (%)", "%.1f", func(s MatchupSummary) float64 { return 100 * s.KillChance }},
+	{"models_killed", "Mean models killed", "%.2f", func(s MatchupSummary) float64 { return s.ModelsKilled }},
+	{"overkill", "Mean overkill damage", "%.2f", func(s MatchupSummary) float64 { return s.MeanOverkill }},
+	{"damage_per_100", "Damage per 100 points", "%.2f", func(s MatchupSummary) float64 { return s.DamagePer100 }},
+	{"points_per_point", "Points destroyed per point", "%.3f", func(s MatchupSummary) float64 { return s.PointsPerPoint }},
+	{"roi", "Return on investment", "%.3f", func(s MatchupSummary) float64 { return s.ReturnOnInvestment }},
+}
+
+// Look up a comma separated list of metric keys such as "mean_damage,kill_chance"
+func parseMatrixMetrics(list string) ([]MatrixMetric, error) {
+	metrics := []MatrixMetric{}
+	for _, key := range strings.Split(list, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		found := false
+		for _, metric := range _matrixMetrics {
+			if metric.Key == key {
+				metrics = append(metrics, metric)
+				found = true
+			}
+		}
+		if !found {
+			known := []string{}
+			for _, metric := range _matrixMetrics {
+				known = append(known, metric.Key)
+			}
+			return nil, fmt.Errorf("unknown metric '%s', expected one of %s", key, strings.Join(known, ", "))
+		}
+	}
+	return metrics, nil
+}
+
+// Select library unit files from a comma separated list of globs ("captain*.yaml"), tags
+// ("tag:Infantry") matched against unit keywords and type, and reference targets ("ref:meq",
+// or "ref:*" for all of them)
+func selectUnits(spec string) ([]string, error) {
+	selected := map[string]bool{}
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if isReferenceTarget(pattern) {
+			if strings.HasSuffix(pattern, "*") {
+				for _, key := range referenceTargetKeys() {
+					selected[_referencePrefix+key] = true
+				}
+			} else if _, err := referenceTarget(pattern); err != nil {
+				return nil, err
+			} else {
+				selected[strings.ToLower(pattern)] = true
+			}
+			continue
+		}
+
+		if strings.HasPrefix(strings.ToLower(pattern), "tag:") {
+			tag := strings.ToLower(strings.TrimSpace(pattern[len("tag:"):]))
+			files, err := filepath.Glob(filepath.Join(_unitLibraryFilepath, "*.yaml"))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				name := filepath.Base(file)
+				unit := loadUnit(name)
+				for _, keyword := range append(append([]string{}, unit.Keywords...), unit.Type) {
+					if strings.ToLower(keyword) == tag {
+						selected[name] = true
+					}
+				}
+			}
+			continue
+		}
+
+		files, err := filepath.Glob(filepath.Join(_unitLibraryFilepath, pattern))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no library units match '%s'", pattern)
+		}
+		for _, file := range files {
+			selected[filepath.Base(file)] = true
+		}
+	}
+
+	names := []string{}
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no library units selected by '%s'", spec)
+	}
+	return names, nil
+}
+
+// Display names for unit files, falling back to the file name where unit names clash
+func matrixLabels(files []string) []string {
+	labels := make([]string, len(files))
+	seen := map[string]int{}
+	for i, file := range files {
+		labels[i] = loadUnit(file).Name
+		seen[labels[i]]++
+	}
+	for i, file := range files {
+		if labels[i] == "" || seen[labels[i]] > 1 {
+			labels[i] = strings.TrimSuffix(file, ".yaml")
+		}
+	}
+	return labels
+}
+
+// Run every attacker against every defender and write a CSV grid per metric plus an HTML heatmap
+func runMatrix(attackerSpec, defenderSpec string, metrics []MatrixMetric, scenario Scenario, workers int, seed int64) error {
+	attackers, err := selectUnits(attackerSpec)
+	if err != nil {
+		return err
+	}
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	attackerLabels := matrixLabels(attackers)
+	defenderLabels := matrixLabels(defenders)
+
+	summaries := make([][]MatchupSummary, len(attackers))
+	for a, attackerFile := range attackers {
+		summaries[a] = make([]MatchupSummary, len(defenders))
+		for d, defenderFile := range defenders {
+			fmt.Printf("Simulating %s against %s\n", attackerLabels[a], defenderLabels[d])
+			conflict := UnitAttackSequence{
+				Attacker: loadUnit(attackerFile),
+				Defender: loadUnit(defenderFile),
+				Scenario: scenario,
+			}
+			results := runSimulations(conflict, _numSimulations, workers, seed, nil)
+			summaries[a][d] = summariseMatchup(results, conflict.Attacker, conflict.Defender)
+		}
+	}
+
+	for _, metric := range metrics {
+		filename := fmt.Sprintf("matrix_%s.csv", metric.Key)
+		if err := writeMatrixCSV(filename, metric, attackerLabels, defenderLabels, summaries); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", filename)
+	}
+	if err := writeMatrixHTML("matrix.html", metrics, attackerLabels, defenderLabels, summaries, scenario); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote matrix.html\n")
+	return nil
+}
+
+// Write one metric as a grid with an attacker per row and a defender per column
+func writeMatrixCSV(filename string, metric MatrixMetric, attackers, defenders []string, summaries [][]MatchupSummary) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(append([]string{"Attacker \\ Defender"}, defenders...))
+	for a, attacker := range attackers {
+		row := []string{attacker}
+		for d := range defenders {
+			row = append(row, fmt.Sprintf(metric.Format, metric.Value(summaries[a][d])))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Write a self-contained HTML page with a heatmap table per metric, shaded from the
+// lowest to the highest value of that metric
+func writeMatrixHTML(filename string, metrics []MatrixMetric, attackers, defenders []string, summaries [][]MatchupSummary, scenario Scenario) error {
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Matchup Matrix</title>\n")
+	page.WriteString("<style>\n" +
+		"body { font-family: sans-serif; margin: 2em; }\n" +
+		"table { border-collapse: collapse; margin-bottom: 2em; }\n" +
+		"th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: center; }\n" +
+		"th { background: #eee; }\n" +
+		"</style>\n</head>\n<body>\n")
+	page.WriteString("<h1>Matchup Matrix</h1>\n")
+	if scenario.Name != "" {
+		page.WriteString(fmt.Sprintf("<p>Scenario: %s</p>\n", html.EscapeString(scenario.Name)))
+	}
+	page.WriteString(fmt.Sprintf("<p>%d simulations per matchup. Rows attack, columns defend.</p>\n", _numSimulations))
+
+	for _, metric := range metrics {
+		low, high := math.Inf(1), math.Inf(-1)
+		for a := range attackers {
+			for d := range defenders {
+				value := metric.Value(summaries[a][d])
+				low = math.Min(low, value)
+				high = math.Max(high, value)
+			}
+		}
+
+		page.WriteString(fmt.Sprintf("<h2>%s</h2>\n<table>\n<tr><th>Attacker \\ Defender</th>", html.EscapeString(metric.Label)))
+		for _, defender := range defenders {
+			page.WriteString(fmt.Sprintf("<th>%s</th>", html.EscapeString(defender)))
+		}
+		page.WriteString("</tr>\n")
+
+		for a, attacker := range attackers {
+			page.WriteString(fmt.Sprintf("<tr><th>%s</th>", html.EscapeString(attacker)))
+			for d := range defenders {
+				value := metric.Value(summaries[a][d])
+				page.WriteString(fmt.Sprintf("<td style=\"background: %s\">%s</td>", heatColour(value, low, high), fmt.Sprintf(metric.Format, value)))
+			}
+			page.WriteString("</tr>\n")
+		}
+		page.WriteString("</table>\n")
+	}
+	page.WriteString("</body>\n</html>\n")
+
+	return os.WriteFile(filename, []byte(page.String()), 0644)
+}
+
+// Shade a value from white at the low end of its range to red at the high end
+func heatColour(value, low, high float64) string {
+	scale := 0.0
+	if high > low {
+		scale = (value - low) / (high - low)
+	}
+	fade := int(math.Round(255 - 155*scale))
+	return fmt.Sprintf("rgb(255, %d, %d)", fade, fade)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Abilities that are stratagems and cannot be used by a Battle-shocked unit
+var _stratagems = []string{"red rampage"}
+
+func isStratagem(ability string) bool {
+	for _, stratagem := range _stratagems {
+		if strings.ToLower(ability) == stratagem {
+			return true
+		}
+	}
+	return false
+}
+
+// Starting strength in models across all model groups
+func (u *Unit) startingStrength() int {
+	total := 0
+	for _, model := range u.Models {
+		total += model.Count
+	}
+	return total
+}
+
+// Below Half-strength: fewer than half the starting models remain, or for a single model
+// unit, fewer than half its wounds remain
+func (u *Unit) belowHalfStrength() bool {
+	starting := u.startingStrength()
+	alive := starting - u.ModelsLost()
+	if alive <= 0 {
+		return false // Destroyed units do not test
+	}
+
+	if starting == 1 {
+		for _, model := range u.Models {
+			if model.Killed < model.Count {
+				return (model.Wounds-model.CarryOverWounds)*2 < model.Wounds
+			}
+		}
+	}
+	return alive*2 < starting
+}
+
+// The best (lowest) Leadership among the unit's surviving models, and whether any of them
+// has a Leadership characteristic. Without one the unit tests against 7+.
+func (u *Unit) leadership() (int, bool) {
+	best, found := 7, false
+	for _, model := range u.Models {
+		if model.Killed >= model.Count {
+			continue
+		}
+		if _, exists := model.Stats["LD"]; !exists {
+			continue
+		}
+		found = true
+		if ld := parseRollThreshold(model.Stats["LD"]); ld < best {
+			best = ld
+		}
+	}
+	return best, found
+}
+
+// Roll 2D6 plus modifiers against the unit's Leadership, Battle-shocking it on a failure
+func (conflict *UnitAttackSequence) battleShockTest(u *Unit, modifier int, reason string) bool {
+	leadership, found := u.leadership()
+	if !found && conflict.logger != nil {
+		conflict.logger.Warn(fmt.Sprintf("%s has no LD characteristic, testing against 7+", u.Name))
+	}
+	roll := rollDice(conflict.dice, 2, 6)
+	passed := roll+modifier >= leadership
+	if !passed {
+		u.BattleShocked = true
+	}
+
+	if conflict.logger != nil {
+		conflict.logger.Info("Battle-shock Test",
+			zap.String("unit", u.Name),
+			zap.String("reason", reason),
+			zap.Int("roll", roll),
+			zap.Int("modifier", modifier),
+			zap.Int("leadership", leadership),
+			zap.Bool("passed", passed))
+	}
+	return passed
+}
+
+// Command phase: Battle-shocked units recover, then units below Half-strength test
+func (conflict *UnitAttackSequence) commandPhase(u *Unit, modifier int) {
+	u.BattleShocked = false
+	if u.belowHalfStrength() {
+		conflict.battleShockTest(u, modifier, "below half-strength")
+	}
+}
+
+// Shadow in the Warp and similar abilities force enemy units to test regardless of strength
+func (conflict *UnitAttackSequence) forcedBattleShockTests() {
+	if abilityCheck("Shadow in the Warp", conflict.Attacker.Abilities) {
+		conflict.battleShockTest(&conflict.Defender, conflict.Scenario.BattleShockModifier, fmt.Sprintf("Shadow in the Warp (%s)", conflict.Attacker.Name))
+	}
+	if abilityCheck("Shadow in the Warp", conflict.Defender.Abilities) {
+		conflict.battleShockTest(&conflict.Attacker, conflict.Scenario.BattleShockModifier, fmt.Sprintf("Shadow in the Warp (%s)", conflict.Defender.Name))
+	}
+}
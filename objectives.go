@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Remaining Objective Control of the unit's surviving models, 0 while Battle-shocked
+func (u *Unit) ObjectiveControl() int {
+	if u.BattleShocked {
+		return 0
+	}
+
+	total := 0
+	for _, model := range u.Models {
+		alive := model.Count - model.Killed
+		if alive <= 0 {
+			continue
+		}
+		if oc, err := strconv.Atoi(strings.TrimSpace(model.Stats["OC"])); err == nil {
+			total += oc * alive
+		}
+	}
+	return total
+}
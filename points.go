@@ -0,0 +1,44 @@
+package main
+
+// Points values come from Unit.Cost. Library files give one cost per unit, so it is
+// spread across the unit's wounds: a model's value is its share of the unit's wounds.
+
+// Points value of a single wound of the unit
+func (u *Unit) pointsPerWound() float64 {
+	totalWounds := u.TotalWounds()
+	if totalWounds == 0 {
+		return 0
+	}
+	return float64(u.Cost) / float64(totalWounds)
+}
+
+// Points destroyed counting every wound removed, including damage on surviving models
+func (u *Unit) PointsLost() float64 {
+	return float64(u.WoundsLost()) * u.pointsPerWound()
+}
+
+// Points destroyed counting only models that were removed
+func (u *Unit) ModelPointsLost() float64 {
+	lost := 0.0
+	for _, model := range u.Models {
+		lost += float64(model.Killed*model.Wounds) * u.pointsPerWound()
+	}
+	return lost
+}
+
+// Damage dealt per 100 points of the attacker
+func damagePer100Points(damage float64, cost int) float64 {
+	if cost == 0 {
+		return 0
+	}
+	return 100 * damage / float64(cost)
+}
+
+// Net points traded per point spent: defender points destroyed, less the attacker's own
+// losses, over the attacker's cost
+func (r SimulationResult) ReturnOnInvestment(cost int) float64 {
+	if cost == 0 {
+		return 0
+	}
+	return (r.DefenderPointsLost - r.AttackerPointsLost) / float64(cost)
+}
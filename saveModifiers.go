@@ -0,0 +1,161 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SaveModifiers collects the defender's rules that change how saves are made
+type SaveModifiers struct {
+	APReduction        int  // Worsens the attack's AP, e.g. Armour of Contempt
+	SaveBonus          int  // Bonus to armour saves, capped at +1 together with cover
+	InvulnerableSave   int  // Best invulnerable save granted by abilities or stratagems, 7 for none
+	InvulnerableCap    int  // Invulnerable saves cannot be better than this, 0 for no cap
+	RangedInvulnerable int  // Invulnerable save against ranged attacks only, 0 for none
+	MeleeInvulnerable  int  // Invulnerable save against melee attacks only, 0 for none
+	RerollSaves        bool // Reroll all failed saves
+	RerollSave1s       bool // Reroll save rolls of 1
+	Sources            []string
+	Skipped            []string // Invulnerable saves with a condition that is not modelled
+}
+
+var (
+	apReductionPattern  = regexp.MustCompile(`(?i)worsen (?:the )?ap (?:characteristic )?(?:by )?(\d)`)
+	saveBonusPattern    = regexp.MustCompile(`(?i)\+(\d) to (?:armou?r )?saves?`)
+	invulnerablePattern = regexp.MustCompile(`(?i)invulnerable save \((\d)\+\)(.*)|(\d)\+ invulnerable save(.*)`)
+	invulnerableCap     = regexp.MustCompile(`(?i)invulnerable saves? cannot be better than (\d)\+`)
+)
+
+// Parse a single ability or stratagem into the modifier set, returning true if it applied
+func (m *SaveModifiers) parse(text string) bool {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	applied := false
+
+	if lower == "armour of contempt" || lower == "armor of contempt" {
+		m.APReduction++
+		applied = true
+	} else if matches := apReductionPattern.FindStringSubmatch(lower); matches != nil {
+		value, _ := strconv.Atoi(matches[1])
+		m.APReduction += value
+		applied = true
+	}
+
+	if matches := saveBonusPattern.FindStringSubmatch(lower); matches != nil {
+		value, _ := strconv.Atoi(matches[1])
+		m.SaveBonus += value
+		applied = true
+	}
+
+	if matches := invulnerableCap.FindStringSubmatch(lower); matches != nil {
+		value, _ := strconv.Atoi(matches[1])
+		if value > m.InvulnerableCap {
+			m.InvulnerableCap = value
+		}
+		applied = true
+	} else if matches := invulnerablePattern.FindStringSubmatch(lower); matches != nil {
+		value, condition := matches[1], matches[2]
+		if value == "" {
+			value, condition = matches[3], matches[4]
+		}
+		invulnerable, _ := strconv.Atoi(value)
+		switch strings.Trim(condition, " .,;") {
+		case "":
+			if invulnerable < m.InvulnerableSave {
+				m.InvulnerableSave = invulnerable
+			}
+			applied = true
+		case "against ranged attacks", "against shooting attacks":
+			if m.RangedInvulnerable == 0 || invulnerable < m.RangedInvulnerable {
+				m.RangedInvulnerable = invulnerable
+			}
+			applied = true
+		case "against melee attacks":
+			if m.MeleeInvulnerable == 0 || invulnerable < m.MeleeInvulnerable {
+				m.MeleeInvulnerable = invulnerable
+			}
+			applied = true
+		default:
+			m.Skipped = append(m.Skipped, text)
+		}
+	}
+
+	if strings.Contains(lower, "reroll saves") || strings.Contains(lower, "re-roll saves") ||
+		strings.Contains(lower, "reroll save rolls") || strings.Contains(lower, "re-roll save rolls") {
+		if strings.Contains(lower, "of 1") {
+			m.RerollSave1s = true
+		} else {
+			m.RerollSaves = true
+		}
+		applied = true
+	}
+
+	if applied {
+		m.Sources = append(m.Sources, text)
+	}
+	return applied
+}
+
+// Build the defender's save modifiers from its abilities and the stratagems it can use
+func (conflict *UnitAttackSequence) defenderSaveModifiers() SaveModifiers {
+	modifiers := SaveModifiers{InvulnerableSave: 7}
+	for _, ability := range conflict.Defender.Abilities {
+		modifiers.parse(ability)
+	}
+
+	// Battle-shocked units cannot use stratagems
+	if !conflict.Defender.BattleShocked {
+		for _, stratagem := range conflict.Scenario.DefenderStratagems {
+			modifiers.parse(stratagem)
+		}
+	}
+	return modifiers
+}
+
+// The modifiers against a single weapon, with any invulnerable save limited to ranged or
+// melee attacks taken when it applies
+func (m SaveModifiers) against(weapon WeaponProfile) SaveModifiers {
+	conditional := m.RangedInvulnerable
+	if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+		conditional = m.MeleeInvulnerable
+	}
+	if conditional > 0 && conditional < m.InvulnerableSave {
+		m.InvulnerableSave = conditional
+	}
+	return m
+}
+
+// Work out the final armour and invulnerable saves needed against a single wound
+func (m SaveModifiers) saveThresholds(sv, isv, ap int, cover bool) (int, int) {
+	// AP reduction cannot turn AP into a bonus
+	ap -= m.APReduction
+	if ap < 0 {
+		ap = 0
+	}
+
+	// Benefit of Cover gives +1 to armour saves, except 3+ or better saves against AP 0
+	bonus := m.SaveBonus
+	if cover && !(sv <= 3 && ap == 0) {
+		bonus++
+	}
+	if bonus > 1 {
+		bonus = 1 // Saves can never be improved by more than 1
+	}
+
+	armour := sv + ap - bonus
+	if armour < 2 {
+		armour = 2 // An unmodified 1 always fails
+	}
+
+	// Invulnerable saves ignore AP and bonuses, so just take the best one available
+	if m.InvulnerableSave < isv {
+		isv = m.InvulnerableSave
+	}
+	if isv < m.InvulnerableCap {
+		isv = m.InvulnerableCap
+	}
+	if isv < 2 {
+		isv = 2
+	}
+	return armour, isv
+}
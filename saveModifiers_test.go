@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestSaveThresholds(t *testing.T) {
+	tests := []struct {
+		name                 string
+		abilities            []string
+		sv, isv, ap          int
+		cover                bool
+		armour, invulnerable int
+	}{
+		{"plain armour save", nil, 3, 7, 1, false, 4, 7},
+		{"armour cannot go below 2+", []string{"+1 to saves"}, 2, 7, 0, false, 2, 7},
+		{"cover improves armour", nil, 4, 7, 1, true, 4, 7},
+		{"cover does not help 3+ against AP 0", nil, 3, 7, 0, true, 3, 7},
+		{"save bonus and cover are capped at +1", []string{"+1 to saves"}, 4, 7, 2, true, 5, 7},
+		{"armour of contempt worsens AP", []string{"Armour of Contempt"}, 3, 7, 2, false, 4, 7},
+		{"AP reduction stops at 0", []string{"Worsen AP by 2"}, 3, 7, 1, false, 3, 7},
+		{"best invulnerable save is taken", []string{"Invulnerable Save (4+)"}, 3, 5, 3, false, 6, 4},
+		{"invulnerable cap worsens a better save", []string{"Invulnerable saves cannot be better than 4+"}, 2, 3, 3, false, 5, 4},
+		{"invulnerable cap leaves a worse save", []string{"Invulnerable saves cannot be better than 4+"}, 2, 5, 3, false, 5, 5},
+	}
+	for _, test := range tests {
+		modifiers := SaveModifiers{InvulnerableSave: 7}
+		for _, ability := range test.abilities {
+			modifiers.parse(ability)
+		}
+		armour, invulnerable := modifiers.saveThresholds(test.sv, test.isv, test.ap, test.cover)
+		if armour != test.armour || invulnerable != test.invulnerable {
+			t.Errorf("%s: got %d+/%d+, want %d+/%d+", test.name, armour, invulnerable, test.armour, test.invulnerable)
+		}
+	}
+}
+
+func TestConditionalInvulnerableSave(t *testing.T) {
+	modifiers := SaveModifiers{InvulnerableSave: 7}
+	modifiers.parse("4+ invulnerable save against ranged attacks")
+	modifiers.parse("Invulnerable Save (5+) against melee attacks")
+	modifiers.parse("3+ invulnerable save while within 6\" of a Character")
+
+	tests := []struct {
+		weaponType string
+		want       int
+	}{
+		{"Ranged Weapons", 4},
+		{"Melee Weapons", 5},
+	}
+	for _, test := range tests {
+		if got := modifiers.against(WeaponProfile{Type: test.weaponType}).InvulnerableSave; got != test.want {
+			t.Errorf("against %s: got %d+, want %d+", test.weaponType, got, test.want)
+		}
+	}
+	if modifiers.InvulnerableSave != 7 || len(modifiers.Skipped) != 1 {
+		t.Errorf("conditional saves leaked: invulnerable %d+, skipped %v", modifiers.InvulnerableSave, modifiers.Skipped)
+	}
+}
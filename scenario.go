@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+const _scenarioLibraryFilepath = "./scenarios/"
+
+// Scenario holds the battlefield situation a matchup is simulated in
+type Scenario struct {
+	Name         string              `yaml:"name"`
+	Rounds       int                 `yaml:"rounds,omitempty"`      // Number of attack rounds, defaults to 1
+	StrikeBack   bool                `yaml:"strike_back,omitempty"` // Surviving defenders attack back after each round
+	MortalWounds []MortalWoundAction `yaml:"mortal_wounds,omitempty"`
+
+	BattleShockModifier int `yaml:"battle_shock_modifier,omitempty"` // Added to every Battle-shock test roll
+
+	Cover   bool   `yaml:"cover,omitempty"`   // Defender has the Benefit of Cover against ranged attacks
+	Terrain string `yaml:"terrain,omitempty"` // Terrain the defender is in, e.g. "ruins" or "open", see _terrainNames
+
+	NotVisible bool `yaml:"not_visible,omitempty"` // Target is out of sight, only Indirect Fire weapons can shoot it
+
+	DefenderStratagems []string `yaml:"defender_stratagems,omitempty"` // e.g. "Armour of Contempt"
+}
+
+// The scenario as seen by the defender attacking back, e.g. for Deadly Demise or a strike
+// back. Mortal wound actions belong to the original attacker, so they are dropped.
+func (s Scenario) reversed() Scenario {
+	s.MortalWounds = nil
+	return s
+}
+
+// Terrain a scenario can name, by the spellings it accepts
+var _terrainNames = map[string]string{
+	"open":       "open",
+	"ruins":      "ruins",
+	"ruin":       "ruins",
+	"woods":      "woods",
+	"wood":       "woods",
+	"craters":    "craters",
+	"crater":     "craters",
+	"barricades": "barricades",
+	"barricade":  "barricades",
+	"debris":     "debris",
+	"hills":      "hills",
+	"hill":       "hills",
+}
+
+// Terrain features that give the Benefit of Cover to units within or behind them
+var _coverTerrain = []string{"ruins", "woods", "craters", "barricades", "debris", "hills"}
+
+// The terrain key a scenario's terrain names, or an error for terrain that is not known
+func normaliseTerrain(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil
+	}
+	if terrain, exists := _terrainNames[name]; exists {
+		return terrain, nil
+	}
+	return "", fmt.Errorf("unknown terrain '%s', expected open or one of %s", name, strings.Join(_coverTerrain, ", "))
+}
+
+// Check whether the defender gets the Benefit of Cover against a weapon
+func (conflict *UnitAttackSequence) hasBenefitOfCover(weapon WeaponProfile) bool {
+	// Cover only helps against ranged attacks
+	if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+		return false
+	}
+	if strings.Contains(strings.ToLower(weapon.GetStringCharacteristic("Keywords")), "ignores cover") {
+		return false
+	}
+
+	// Targets of Indirect Fire that are not visible always have the Benefit of Cover
+	if conflict.isIndirectFire(weapon) {
+		return true
+	}
+
+	if conflict.Scenario.Cover || abilityCheck("Benefit of Cover", conflict.Defender.Abilities) {
+		return true
+	}
+	for _, coverTerrain := range _coverTerrain {
+		if conflict.Scenario.Terrain == coverTerrain {
+			return true
+		}
+	}
+	return false
+}
+
+// MortalWoundAction is a damage source with no weapon profile, such as a psychic
+// attack or "roll a D6, on a 2+ the target suffers D3 mortal wounds"
+type MortalWoundAction struct {
+	Name   string `yaml:"name"`
+	Rolls  string `yaml:"rolls,omitempty"` // Number of D6 rolled, defaults to 1
+	Roll   string `yaml:"roll,omitempty"`  // Result needed on each D6, e.g. "2+"; empty always succeeds
+	Damage string `yaml:"damage"`          // Mortal wounds inflicted per success, e.g. "D3"
+}
+
+func loadScenario(name string) Scenario {
+	var (
+		data []byte
+		err  error
+	)
+
+	if data, err = os.ReadFile(_scenarioLibraryFilepath + name); err != nil {
+		panic(err)
+	}
+	scenario := Scenario{}
+	if err = yaml.Unmarshal(data, &scenario); err != nil {
+		panic(err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(name, ".yaml")
+	}
+	if scenario.Terrain, err = normaliseTerrain(scenario.Terrain); err != nil {
+		panic(err)
+	}
+
+	return scenario
+}
+
+// Resolve a mortal wound action against the defender, returning the damage applied
+func (conflict *UnitAttackSequence) resolveMortalWoundAction(action MortalWoundAction) int {
+	rolls := 1
+	if action.Rolls != "" {
+		var err error
+		if rolls, err = rollExpression(conflict.dice, action.Rolls); err != nil {
+			if conflict.logger != nil {
+				conflict.logger.Warn(fmt.Sprintf("Could not parse rolls '%s' for %s: %v", action.Rolls, action.Name, err))
+			}
+			return 0
+		}
+	}
+
+	threshold := 0
+	if action.Roll != "" {
+		threshold = parseRollThreshold(action.Roll)
+	}
+
+	mortalWounds := 0
+	for i := 0; i < rolls; i++ {
+		roll := 0
+		if threshold > 0 {
+			roll = rollDice(conflict.dice, 1, 6)
+			if roll < threshold {
+				if conflict.logger != nil {
+					conflict.logger.Info(fmt.Sprintf("%s: Roll %d rolled %d (need %d+), no effect", action.Name, i+1, roll, threshold))
+				}
+				continue
+			}
+		}
+
+		amount, err := rollExpression(conflict.dice, action.Damage)
+		if err != nil {
+			if conflict.logger != nil {
+				conflict.logger.Warn(fmt.Sprintf("Could not parse damage '%s' for %s: %v", action.Damage, action.Name, err))
+			}
+			return 0
+		}
+		mortalWounds += amount
+
+		if conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("%s: Roll %d rolled %d (need %d+), inflicting %d mortal wounds", action.Name, i+1, roll, threshold, amount))
+		}
+	}
+
+	damageApplied := conflict.allocateMortalWounds(mortalWounds, action.Name)
+
+	if conflict.logger != nil {
+		conflict.logger.Info("Mortal Wound Action Complete",
+			zap.String("action", action.Name),
+			zap.Int("rolls", rolls),
+			zap.Int("mortal_wounds", mortalWounds),
+			zap.Int("damage_applied", damageApplied))
+	}
+
+	return damageApplied
+}
+
+// Run every round of the scenario, carrying wound state over between rounds
+func (conflict *UnitAttackSequence) simulate() (map[string]int, int) {
+	rounds := conflict.Scenario.Rounds
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	damageByLoadout := make(map[string]int)
+	totalDamage := 0
+	for round := 1; round <= rounds; round++ {
+		if conflict.logger != nil && rounds > 1 {
+			conflict.logger.Info(fmt.Sprintf("=== Round %d of %d ===", round, rounds))
+		}
+
+		// Each new round starts with a command phase for both sides
+		if round == 1 {
+			conflict.forcedBattleShockTests()
+		} else {
+			conflict.commandPhase(&conflict.Attacker, conflict.Scenario.BattleShockModifier)
+			conflict.commandPhase(&conflict.Defender, conflict.Scenario.BattleShockModifier)
+		}
+
+		roundDamage, damage := conflict.loadoutAttackSequence()
+		for name, amount := range roundDamage {
+			damageByLoadout[name] += amount
+		}
+		totalDamage += damage
+
+		// Fight exchange: whatever survived hits back, possibly with a damaged profile
+		if conflict.Scenario.StrikeBack && conflict.allocationTarget() >= 0 {
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Strike Back: %s attacks %s", conflict.Defender.Name, conflict.Attacker.Name))
+			}
+			reverse := conflict.reversed()
+			reverse.loadoutAttackSequence()
+			conflict.Defender = reverse.Attacker // Keep any stratagem use and Battle-shock
+		}
+
+		// Stop once either side has been wiped out
+		if conflict.allocationTarget() < 0 {
+			break
+		}
+		reverse := conflict.reversed()
+		if reverse.allocationTarget() < 0 {
+			break
+		}
+	}
+
+	// The Command phase after the last round is the same test as the one between rounds, and
+	// decides whether each side ends the combat Battle-shocked
+	conflict.commandPhase(&conflict.Attacker, conflict.Scenario.BattleShockModifier)
+	conflict.commandPhase(&conflict.Defender, conflict.Scenario.BattleShockModifier)
+
+	return damageByLoadout, totalDamage
+}
+
+// Check whether a weapon is firing indirectly at a target that is not visible
+func (conflict *UnitAttackSequence) isIndirectFire(weapon WeaponProfile) bool {
+	return conflict.Scenario.NotVisible &&
+		strings.Contains(strings.ToLower(weapon.Type), "ranged") &&
+		strings.Contains(strings.ToLower(weapon.GetStringCharacteristic("Keywords")), "indirect fire")
+}
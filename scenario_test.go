@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestNormaliseTerrain(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{"", "", false},
+		{"open", "open", false},
+		{" Ruins ", "ruins", false},
+		{"ruin", "ruins", false},
+		{"Wood", "woods", false},
+		{"hill", "hills", false},
+		{"swamp", "", true},
+		{"ruinss", "", true},
+	}
+	for _, test := range tests {
+		got, err := normaliseTerrain(test.name)
+		if got != test.want || (err != nil) != test.err {
+			t.Errorf("normaliseTerrain(%q) = %q, %v, want %q (error %t)", test.name, got, err, test.want, test.err)
+		}
+	}
+}
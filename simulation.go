@@ -0,0 +1,166 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// SimulationResult is the outcome of a single simulated combat
+type SimulationResult struct {
+	DamageByLoadout       map[string]int
+	TotalDamage           int
+	AttackerWoundsLost    int // Losses from Deadly Demise, Fights on Death and strike backs
+	AttackerModelsLost    int
+	AttackerBattleShocked bool
+	DefenderBattleShocked bool
+	AttackerOC            int // Objective Control left on a contested objective
+	DefenderOC            int
+
+	DefenderWoundsLost int   // Wounds actually removed from the defender
+	DefenderModelsLost int   // Defender models destroyed
+	ModelsKilled       []int // Defender models destroyed, indexed like Defender.Models
+	DefenderDestroyed  bool
+	Overkill           int // Damage wasted beyond the wounds the target model had left
+	DamageIgnored      int // Damage prevented by Feel No Pain and damage reduction
+
+	DefenderPointsLost      float64 // Proportional to the wounds removed
+	DefenderModelPointsLost float64 // Whole models only
+	AttackerPointsLost      float64 // Proportional to the wounds the attacker lost
+}
+
+// Damage columns reported for a matchup: every attacker weapon plus the scenario's
+// mortal wound actions, sorted so output is stable between runs
+func (conflict *UnitAttackSequence) damageColumns() []string {
+	seen := make(map[string]bool)
+	columns := []string{}
+	for _, model := range conflict.Attacker.Models {
+		for weaponName := range model.Loadouts {
+			if !seen[weaponName] {
+				seen[weaponName] = true
+				columns = append(columns, weaponName)
+			}
+		}
+	}
+	for _, action := range conflict.Scenario.MortalWounds {
+		if !seen[action.Name] {
+			seen[action.Name] = true
+			columns = append(columns, action.Name)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// Run one simulation from a clean combat state and record its outcome
+func (conflict *UnitAttackSequence) runSimulation() SimulationResult {
+	conflict.Attacker.Reset()
+	conflict.Defender.Reset()
+	conflict.tally = CombatTally{}
+
+	damageByLoadout, totalDamage := conflict.simulate()
+
+	modelsKilled := make([]int, len(conflict.Defender.Models))
+	for i, model := range conflict.Defender.Models {
+		modelsKilled[i] = model.Killed
+	}
+
+	return SimulationResult{
+		DamageByLoadout:       damageByLoadout,
+		TotalDamage:           totalDamage,
+		AttackerWoundsLost:    conflict.Attacker.WoundsLost(),
+		AttackerModelsLost:    conflict.Attacker.ModelsLost(),
+		AttackerBattleShocked: conflict.Attacker.BattleShocked,
+		DefenderBattleShocked: conflict.Defender.BattleShocked,
+		AttackerOC:            conflict.Attacker.ObjectiveControl(),
+		DefenderOC:            conflict.Defender.ObjectiveControl(),
+		DefenderWoundsLost:    conflict.Defender.WoundsLost(),
+		DefenderModelsLost:    conflict.Defender.ModelsLost(),
+		ModelsKilled:          modelsKilled,
+		DefenderDestroyed:     conflict.allocationTarget() < 0,
+		Overkill:              conflict.tally.Overkill,
+		DamageIgnored:         conflict.tally.DamageIgnored,
+
+		DefenderPointsLost:      conflict.Defender.PointsLost(),
+		DefenderModelPointsLost: conflict.Defender.ModelPointsLost(),
+		AttackerPointsLost:      conflict.Attacker.PointsLost(),
+	}
+}
+
+// Seed for one simulation's own random stream. Mixing the run seed with the simulation
+// index (splitmix64) gives every simulation an independent stream, so any single
+// simulation can be replayed without running the ones before it.
+func simulationSeed(seed int64, index int) int64 {
+	z := uint64(seed) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Dice for one simulation of a run
+func newDiceSource(seed int64, index int) DiceSource {
+	return rand.New(rand.NewSource(simulationSeed(seed, index)))
+}
+
+// Run count simulations across a pool of workers. newWorker is called once per worker to
+// set up that worker's own state and returns the function that runs one simulation by
+// index. Workers take every workers-th index, so callers can store results by index.
+func runWorkers(count, workers int, newWorker func() func(index int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > count {
+		workers = count
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			simulate := newWorker()
+			for i := worker; i < count; i += workers {
+				simulate(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// Run a matchup's simulations across a pool of workers. Each worker owns a clone of the
+// units and its own derived weapons, and each simulation rolls from its own stream, so
+// results come back in simulation order and depend only on the seed. Only the first
+// simulation is written to the combat log.
+func runSimulations(template UnitAttackSequence, count, workers int, seed int64, logger *zap.Logger) []SimulationResult {
+	results := make([]SimulationResult, count)
+	runWorkers(count, workers, func() func(int) {
+		conflict := UnitAttackSequence{
+			Attacker: template.Attacker.Clone(),
+			Defender: template.Defender.Clone(),
+			Scenario: template.Scenario,
+		}
+		return func(i int) {
+			conflict.dice = newDiceSource(seed, i)
+			conflict.logger = nil
+			if i == 0 {
+				conflict.logger = logger
+			}
+			results[i] = conflict.runSimulation()
+		}
+	})
+	return results
+}
+
+// Re-run a single simulation of a run exactly, writing it to the combat log
+func replaySimulation(template UnitAttackSequence, index int, seed int64, logger *zap.Logger) SimulationResult {
+	conflict := UnitAttackSequence{
+		Attacker: template.Attacker.Clone(),
+		Defender: template.Defender.Clone(),
+		Scenario: template.Scenario,
+		dice:     newDiceSource(seed, index),
+		logger:   logger,
+	}
+	return conflict.runSimulation()
+}
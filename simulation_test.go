@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestSimulationSeed(t *testing.T) {
+	// The same run seed and index always give the same stream
+	if simulationSeed(42, 7) != simulationSeed(42, 7) {
+		t.Error("simulationSeed is not deterministic")
+	}
+
+	// Neighbouring simulations and runs get different streams
+	seen := map[int64]bool{}
+	for _, seed := range []int64{0, 1, 42, -1} {
+		for index := 0; index < 100; index++ {
+			value := simulationSeed(seed, index)
+			if seen[value] {
+				t.Fatalf("simulationSeed(%d, %d) repeats an earlier seed", seed, index)
+			}
+			seen[value] = true
+		}
+	}
+
+	// A replayed simulation rolls exactly what it rolled in the run
+	run, replay := newDiceSource(42, 399), newDiceSource(42, 399)
+	for i := 0; i < 20; i++ {
+		if a, b := run.Intn(6), replay.Intn(6); a != b {
+			t.Fatalf("roll %d differs between run (%d) and replay (%d)", i, a, b)
+		}
+	}
+}
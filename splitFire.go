@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits on the split fire search, which tries every division of each weapon's models
+// between the targets
+const (
+	_maxSplitSubsets = 256 // Most weapon subsets simulated against each target
+	_maxSplitTargets = 4
+)
+
+// SplitAssignment is one model group's weapon, whose models can each fire at a different target
+type SplitAssignment struct {
+	Group  int
+	Weapon string
+	Models int
+	Label  string
+}
+
+// Every model group and weapon pair of the unit that can be given its own targets
+func splitAssignments(unit Unit) []SplitAssignment {
+	assignments := []SplitAssignment{}
+	for _, step := range unit.firingSteps() {
+		model := unit.Models[step.Group]
+		assignments = append(assignments, SplitAssignment{
+			Group:  step.Group,
+			Weapon: step.Weapon,
+			Models: model.Count,
+			Label:  unit.stepLabel(step),
+		})
+	}
+	return assignments
+}
+
+// Number of weapon subsets, where a subset fires each assignment with 0 to all of its models
+func splitSubsets(assignments []SplitAssignment) int {
+	subsets := 1
+	for _, assignment := range assignments {
+		subsets *= assignment.Models + 1
+	}
+	return subsets
+}
+
+// Index of the weapon subset firing each assignment with the given number of models
+func splitSubset(assignments []SplitAssignment, counts []int) int {
+	subset, stride := 0, 1
+	for i, assignment := range assignments {
+		subset += counts[i] * stride
+		stride *= assignment.Models + 1
+	}
+	return subset
+}
+
+// Number of models firing each assignment in a weapon subset
+func splitCounts(assignments []SplitAssignment, subset int) []int {
+	counts := make([]int, len(assignments))
+	for i, assignment := range assignments {
+		counts[i] = subset % (assignment.Models + 1)
+		subset /= assignment.Models + 1
+	}
+	return counts
+}
+
+// Every way of dividing a number of models between the targets
+func splitDivisions(models, targets int) [][]int {
+	if targets == 1 {
+		return [][]int{{models}}
+	}
+	divisions := [][]int{}
+	for first := models; first >= 0; first-- {
+		for _, rest := range splitDivisions(models-first, targets-1) {
+			divisions = append(divisions, append([]int{first}, rest...))
+		}
+	}
+	return divisions
+}
+
+// Weapon subset fired at each target when each assignment's models are divided by the chosen
+// division
+func splitTargetSubsets(assignments []SplitAssignment, divisions [][][]int, choices []int, targets int) []int {
+	subsets := make([]int, targets)
+	for d := range subsets {
+		counts := make([]int, len(assignments))
+		for i := range assignments {
+			counts[i] = divisions[i][choices[i]][d]
+		}
+		subsets[d] = splitSubset(assignments, counts)
+	}
+	return subsets
+}
+
+// Copy of the attacker firing a weapon subset, in its usual firing order. The models of each
+// model group that fire are layered, so a model firing one weapon at the target fires every
+// other weapon that more of its group fire there.
+func splitAttacker(base Unit, assignments []SplitAssignment, counts []int) Unit {
+	attacker := base.Clone()
+	attacker.FiringOrder = base.stepLabels(base.firingSteps())
+	attacker.Models = []ModelData{}
+	for group, model := range base.Models {
+		firing := map[string]int{}
+		for i, assignment := range assignments {
+			if assignment.Group == group && counts[i] > 0 {
+				firing[assignment.Weapon] = counts[i]
+			}
+		}
+		for len(firing) > 0 {
+			layer := model
+			layer.Loadouts = map[string]WeaponProfile{}
+			layer.Count = 0
+			for weapon, count := range firing {
+				layer.Loadouts[weapon] = model.Loadouts[weapon]
+				if layer.Count == 0 || count < layer.Count {
+					layer.Count = count
+				}
+			}
+			for weapon := range firing {
+				if firing[weapon] -= layer.Count; firing[weapon] == 0 {
+					delete(firing, weapon)
+				}
+			}
+			attacker.Models = append(attacker.Models, layer)
+		}
+	}
+	return attacker
+}
+
+// Split the attacker's weapons across the defenders, trying every division of each model
+// group weapon's models between the targets. Targets are attacked independently, so each
+// subset of weapons is simulated once per target and allocations are scored from those
+// results. With a priority target the chance to destroy it is maximised, otherwise total
+// points destroyed, or wounds removed when any defender has no points cost.
+func runSplitFire(attackerFile, defenderSpec, priority string, scenario Scenario, workers int, seed int64) error {
+	base := loadUnit(attackerFile)
+	assignments := splitAssignments(base)
+	if len(assignments) == 0 {
+		return fmt.Errorf("%s has no weapons to split", base.Name)
+	}
+	subsets := splitSubsets(assignments)
+	if subsets > _maxSplitSubsets {
+		return fmt.Errorf("%s has %d weapon subsets, split fire is limited to %d", base.Name, subsets, _maxSplitSubsets)
+	}
+
+	defenders, err := selectUnits(defenderSpec)
+	if err != nil {
+		return err
+	}
+	if len(defenders) > _maxSplitTargets {
+		return fmt.Errorf("%d defenders selected, split fire is limited to %d targets", len(defenders), _maxSplitTargets)
+	}
+	defenderLabels := matrixLabels(defenders)
+
+	// Points and wounds cannot be added together, so one target without a cost means wounds
+	uncosted := []string{}
+	for d, defenderFile := range defenders {
+		if loadUnit(defenderFile).Cost <= 0 {
+			uncosted = append(uncosted, defenderLabels[d])
+		}
+	}
+	useWounds := len(uncosted) > 0
+	valueName := "points destroyed"
+	if useWounds {
+		valueName = "wounds removed"
+	}
+
+	priorityIndex := -1
+	if priority != "" {
+		for d, defenderFile := range defenders {
+			if strings.EqualFold(defenderFile, priority) {
+				priorityIndex = d
+			}
+		}
+		if priorityIndex < 0 {
+			return fmt.Errorf("priority target '%s' is not one of the defenders %s", priority, strings.Join(defenders, ", "))
+		}
+	}
+
+	// Summary of every subset of weapons against every target, on the same seed
+	summaries := make([][]MatchupSummary, len(defenders))
+	for d, defenderFile := range defenders {
+		fmt.Printf("Simulating %d weapon subsets against %s\n", subsets-1, defenderLabels[d])
+		summaries[d] = make([]MatchupSummary, subsets)
+		for subset := 1; subset < subsets; subset++ {
+			conflict := UnitAttackSequence{
+				Attacker: splitAttacker(base, assignments, splitCounts(assignments, subset)),
+				Defender: loadUnit(defenderFile),
+				Scenario: scenario,
+			}
+			results := runSimulations(conflict, _numSimulations, workers, seed, nil)
+			summaries[d][subset] = summariseMatchup(results, conflict.Attacker, conflict.Defender)
+		}
+	}
+
+	// Score every allocation, which picks one division of its models for each assignment
+	divisions := make([][][]int, len(assignments))
+	for i, assignment := range assignments {
+		divisions[i] = splitDivisions(assignment.Models, len(defenders))
+	}
+	choices := make([]int, len(assignments))
+	bestChoices := []int{}
+	bestValue, bestKill := -1.0, -1.0
+	for {
+		targetSubsets := splitTargetSubsets(assignments, divisions, choices, len(defenders))
+		value, kill := 0.0, 0.0
+		for d, subset := range targetSubsets {
+			value += splitValue(summaries[d][subset], useWounds)
+		}
+		if priorityIndex >= 0 {
+			kill = summaries[priorityIndex][targetSubsets[priorityIndex]].KillChance
+		}
+		if kill > bestKill || (kill == bestKill && value > bestValue) {
+			bestChoices, bestValue, bestKill = append([]int{}, choices...), value, kill
+		}
+
+		// Move on to the next allocation, stopping once every one has been scored
+		i := 0
+		for ; i < len(choices); i++ {
+			if choices[i]++; choices[i] < len(divisions[i]) {
+				break
+			}
+			choices[i] = 0
+		}
+		if i == len(choices) {
+			break
+		}
+	}
+
+	fmt.Printf("=== Split fire: %s ===\n", base.Name)
+	if len(uncosted) > 0 && len(uncosted) < len(defenders) {
+		fmt.Printf("%s has no points cost, so wounds removed are compared instead of points\n", strings.Join(uncosted, ", "))
+	}
+	if priorityIndex >= 0 {
+		fmt.Printf("Maximising the chance to destroy %s, then %s\n", defenderLabels[priorityIndex], valueName)
+	} else {
+		fmt.Printf("Maximising %s\n", valueName)
+	}
+	width := len("Weapon")
+	for _, assignment := range assignments {
+		if len(assignment.Label) > width {
+			width = len(assignment.Label)
+		}
+	}
+	fmt.Printf("%-*s  %s\n", width, "Weapon", "Targets")
+	bestSubsets := splitTargetSubsets(assignments, divisions, bestChoices, len(defenders))
+	for i, assignment := range assignments {
+		targets := []string{}
+		for d, count := range divisions[i][bestChoices[i]] {
+			if count > 0 && assignment.Models == 1 {
+				targets = append(targets, defenderLabels[d])
+			} else if count > 0 {
+				targets = append(targets, fmt.Sprintf("%d at %s", count, defenderLabels[d]))
+			}
+		}
+		fmt.Printf("%-*s  %s\n", width, assignment.Label, strings.Join(targets, ", "))
+	}
+
+	fmt.Printf("\n%-30s  %11s  %9s  %13s  %11s  %6s\n", "Target", "Mean damage", "Destroyed", "Models killed", "Wounds lost", "Points")
+	for d := range defenders {
+		summary := summaries[d][bestSubsets[d]]
+		fmt.Printf("%-30s  %11.2f  %8.1f%%  %13.2f  %11.2f  %6.1f\n",
+			defenderLabels[d], summary.MeanDamage, 100*summary.KillChance, summary.ModelsKilled, summary.WoundsLost, summary.PointsLost)
+	}
+	fmt.Printf("Total %s: %.2f\n", valueName, bestValue)
+
+	// Compare with the whole unit shooting a single target
+	for d := range defenders {
+		summary := summaries[d][subsets-1]
+		fmt.Printf("All at %s: %.2f %s, %.1f%% destroyed\n", defenderLabels[d], splitValue(summary, useWounds), valueName, 100*summary.KillChance)
+	}
+	return nil
+}
+
+// Value of a target's result to the split fire solver
+func splitValue(summary MatchupSummary, useWounds bool) float64 {
+	if useWounds {
+		return summary.WoundsLost
+	}
+	return summary.PointsLost
+}
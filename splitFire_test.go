@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDivisions(t *testing.T) {
+	want := [][]int{{2, 0}, {1, 1}, {0, 2}}
+	if got := splitDivisions(2, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitAttacker(t *testing.T) {
+	base := Unit{Models: []ModelData{{
+		Name:     "Marine",
+		Count:    5,
+		Loadouts: map[string]WeaponProfile{"Bolt Rifle": {}, "Bolt Pistol": {}},
+	}}}
+	assignments := splitAssignments(base)
+	if subsets := splitSubsets(assignments); subsets != 36 {
+		t.Fatalf("got %d subsets, want 36", subsets)
+	}
+
+	// 3 models fire their bolt pistols and 1 of them also fires its bolt rifle
+	counts := map[string]int{"Bolt Pistol": 3, "Bolt Rifle": 1}
+	subset := make([]int, len(assignments))
+	for i, assignment := range assignments {
+		subset[i] = counts[assignment.Weapon]
+	}
+	attacker := splitAttacker(base, assignments, subset)
+	if len(attacker.Models) != 2 {
+		t.Fatalf("got %d model groups, want 2", len(attacker.Models))
+	}
+	if got := attacker.Models[0]; got.Count != 1 || len(got.Loadouts) != 2 {
+		t.Errorf("first layer: got %d models with %d weapons, want 1 with 2", got.Count, len(got.Loadouts))
+	}
+	if got := attacker.Models[1]; got.Count != 2 || len(got.Loadouts) != 1 {
+		t.Errorf("second layer: got %d models with %d weapons, want 2 with 1", got.Count, len(got.Loadouts))
+	}
+	if len(base.Models[0].Loadouts) != 2 {
+		t.Errorf("base unit's loadouts were changed")
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Quantiles reported when none are given with -quantiles
+const _defaultQuantiles = "0.05,0.25,0.5,0.75,0.95"
+
+// z-score for a 95% confidence interval on the mean
+const _confidenceZ = 1.96
+
+// Distribution summarises a whole-number outcome, such as damage dealt or models killed,
+// across a set of simulations
+type Distribution struct {
+	Samples   []int // Sorted ascending
+	Histogram []int // Number of simulations with exactly each outcome
+	Mean      float64
+	StdDev    float64 // Sample standard deviation
+}
+
+func newDistribution(outcomes []int) Distribution {
+	d := Distribution{Samples: append([]int(nil), outcomes...)}
+	sort.Ints(d.Samples)
+	if len(d.Samples) == 0 {
+		return d
+	}
+
+	d.Histogram = make([]int, d.Samples[len(d.Samples)-1]+1)
+	for _, outcome := range d.Samples {
+		d.Histogram[outcome]++
+		d.Mean += float64(outcome)
+	}
+	d.Mean /= float64(len(d.Samples))
+
+	if len(d.Samples) > 1 {
+		variance := 0.0
+		for _, outcome := range d.Samples {
+			variance += (float64(outcome) - d.Mean) * (float64(outcome) - d.Mean)
+		}
+		d.StdDev = math.Sqrt(variance / float64(len(d.Samples)-1))
+	}
+	return d
+}
+
+// Median outcome, averaging the middle pair for an even number of simulations
+func (d Distribution) Median() float64 {
+	n := len(d.Samples)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return float64(d.Samples[n/2])
+	}
+	return float64(d.Samples[n/2-1]+d.Samples[n/2]) / 2
+}
+
+// Outcome at quantile q (0-1) by the nearest-rank method: at least q of the simulations
+// came out at this value or less
+func (d Distribution) Quantile(q float64) int {
+	if len(d.Samples) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q*float64(len(d.Samples)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(d.Samples) {
+		rank = len(d.Samples) - 1
+	}
+	return d.Samples[rank]
+}
+
+// 95% confidence interval on the mean
+func (d Distribution) MeanConfidenceInterval() (float64, float64) {
+	if len(d.Samples) == 0 {
+		return 0, 0
+	}
+	margin := _confidenceZ * d.StdDev / math.Sqrt(float64(len(d.Samples)))
+	return d.Mean - margin, d.Mean + margin
+}
+
+// Chance a simulation came out at exactly this value
+func (d Distribution) Exactly(value int) float64 {
+	if value < 0 || value >= len(d.Histogram) {
+		return 0
+	}
+	return float64(d.Histogram[value]) / float64(len(d.Samples))
+}
+
+// Chance a simulation came out at this value or less
+func (d Distribution) CDF(value int) float64 {
+	if len(d.Samples) == 0 {
+		return 0
+	}
+	return float64(sort.SearchInts(d.Samples, value+1)) / float64(len(d.Samples))
+}
+
+// Chance a simulation came out at this value or more
+func (d Distribution) AtLeast(value int) float64 {
+	if len(d.Samples) == 0 {
+		return 0
+	}
+	return 1 - d.CDF(value-1)
+}
+
+// Parse a comma separated list of quantiles such as "0.1,0.5,0.9"
+func parseQuantiles(list string) ([]float64, error) {
+	quantiles := []float64{}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		q, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantile '%s': %v", part, err)
+		}
+		if q < 0 || q > 1 {
+			return nil, fmt.Errorf("quantile %s is outside 0-1", part)
+		}
+		quantiles = append(quantiles, q)
+	}
+	return quantiles, nil
+}
+
+// Highest value worth tabulating: the given limit, such as the defender's total wounds,
+// or more if a simulation went past it
+func (d Distribution) tableLimit(limit int) int {
+	if len(d.Histogram)-1 > limit {
+		limit = len(d.Histogram) - 1
+	}
+	return limit
+}
+
+// Print the mean, spread and requested quantiles, e.g. for label "damage"
+func (d Distribution) printSummary(label string, quantiles []float64) {
+	low, high := d.MeanConfidenceInterval()
+	fmt.Printf("Mean %s: %.2f (95%% CI %.2f-%.2f)\n", label, d.Mean, low, high)
+	fmt.Printf("Standard deviation: %.2f\n", d.StdDev)
+	fmt.Printf("Median %s: %.1f\n", label, d.Median())
+	for _, q := range quantiles {
+		fmt.Printf("%s percentile: %d\n", ordinal(q*100), d.Quantile(q))
+	}
+}
+
+// Print a histogram with the chance of exactly, at most and at least each value up to limit
+func (d Distribution) printTable(label string, limit int) {
+	fmt.Printf("%6s  Exactly  At most  At least\n", label)
+	for value := 0; value <= d.tableLimit(limit); value++ {
+		exactly := d.Exactly(value)
+		fmt.Printf("%6d  %6.1f%%  %6.1f%%  %7.1f%%  %s\n",
+			value,
+			100*exactly,
+			100*d.CDF(value),
+			100*d.AtLeast(value),
+			strings.Repeat("#", int(math.Round(50*exactly))))
+	}
+}
+
+// Write the histogram table up to limit to a CSV file, e.g. for label "Damage"
+func (d Distribution) writeCSV(filename, label string, limit int) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{label, "Simulations",
+		fmt.Sprintf("P(%s = N)", label),
+		fmt.Sprintf("P(%s <= N)", label),
+		fmt.Sprintf("P(%s >= N)", label)})
+	for value := 0; value <= d.tableLimit(limit); value++ {
+		count := 0
+		if value < len(d.Histogram) {
+			count = d.Histogram[value]
+		}
+		writer.Write([]string{
+			fmt.Sprintf("%d", value),
+			fmt.Sprintf("%d", count),
+			fmt.Sprintf("%.4f", d.Exactly(value)),
+			fmt.Sprintf("%.4f", d.CDF(value)),
+			fmt.Sprintf("%.4f", d.AtLeast(value)),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Format a percentile as "5th", "50th", "2.5th" and so on
+func ordinal(percent float64) string {
+	percent = math.Round(percent*1e6) / 1e6 // Hide floating point noise such as 0.07*100
+	number := strconv.FormatFloat(percent, 'f', -1, 64)
+	if percent != math.Trunc(percent) {
+		return number + "th"
+	}
+	whole := int(percent)
+	suffix := "th"
+	if whole%100 < 11 || whole%100 > 13 {
+		switch whole % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return number + suffix
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestQuantile(t *testing.T) {
+	distribution := newDistribution([]int{5, 1, 4, 2, 3, 10, 7, 6, 9, 8})
+	tests := []struct {
+		q    float64
+		want int
+	}{
+		{0, 1},
+		{0.1, 1},
+		{0.25, 3},
+		{0.5, 5},
+		{0.9, 9},
+		{0.95, 10},
+		{1, 10},
+	}
+	for _, test := range tests {
+		if got := distribution.Quantile(test.q); got != test.want {
+			t.Errorf("Quantile(%v) = %d, want %d", test.q, got, test.want)
+		}
+	}
+	if got := newDistribution(nil).Quantile(0.5); got != 0 {
+		t.Errorf("Quantile of no samples = %d, want 0", got)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Defender characteristics a sweep can vary. ISV and FNP use 7 for none.
+var _sweepAxes = []string{"T", "SV", "ISV", "W", "FNP", "MODELS"}
+
+// SweepAxis is one defender characteristic and the values it takes across the grid
+type SweepAxis struct {
+	Name   string
+	Values []int
+}
+
+// Parse a sweep such as "T=3-12;SV=2,3,4;FNP=5,7" into axes
+func parseSweep(spec string) ([]SweepAxis, error) {
+	axes := []SweepAxis{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, list, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("sweep axis '%s' should look like T=3-12", part)
+		}
+		name = strings.ToUpper(strings.TrimSpace(name))
+
+		known := false
+		for _, axis := range _sweepAxes {
+			if axis == name {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown sweep axis '%s', expected one of %s", name, strings.Join(_sweepAxes, ", "))
+		}
+
+		values, err := parseSweepValues(list)
+		if err != nil {
+			return nil, fmt.Errorf("sweep axis %s: %v", name, err)
+		}
+		for _, value := range values {
+			if value < 1 {
+				return nil, fmt.Errorf("sweep axis %s: values must be at least 1", name)
+			}
+			if name == "FNP" && value < 4 {
+				return nil, fmt.Errorf("sweep axis FNP: Feel No Pain of %d+ is not supported, use 4, 5, 6 or 7 for none", value)
+			}
+		}
+		axes = append(axes, SweepAxis{Name: name, Values: values})
+	}
+	if len(axes) == 0 {
+		return nil, fmt.Errorf("no sweep axes given")
+	}
+	return axes, nil
+}
+
+// Parse a comma separated list of values and inclusive ranges, e.g. "2-4,6"
+func parseSweepValues(list string) ([]int, error) {
+	values := []int{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSuffix(strings.TrimSpace(item), "+")
+		if item == "" {
+			continue
+		}
+		if from, to, isRange := strings.Cut(item, "-"); isRange {
+			low, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(from), "+"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid range '%s'", item)
+			}
+			high, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(to), "+"))
+			if err != nil || high < low {
+				return nil, fmt.Errorf("invalid range '%s'", item)
+			}
+			for value := low; value <= high; value++ {
+				values = append(values, value)
+			}
+			continue
+		}
+		value, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s'", item)
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values")
+	}
+	return values, nil
+}
+
+// Set this axis's characteristic on every model group of the unit. The unit must own its
+// Stats maps, see sweepDefender.
+func (axis SweepAxis) apply(unit *Unit, value int) {
+	switch axis.Name {
+	case "FNP":
+		abilities := []string{}
+		for _, ability := range unit.Abilities {
+			if !strings.Contains(strings.ToLower(ability), "feel no pain") {
+				abilities = append(abilities, ability)
+			}
+		}
+		if value < 7 {
+			abilities = append(abilities, fmt.Sprintf("Feel No Pain %d+", value))
+		}
+		unit.Abilities = abilities
+		unit.UnitAbilities = abilities
+		return
+	}
+
+	for i := range unit.Models {
+		model := &unit.Models[i]
+		switch axis.Name {
+		case "T":
+			model.Stats["T"] = strconv.Itoa(value)
+		case "SV":
+			model.Stats["SV"] = fmt.Sprintf("%d+", value)
+		case "ISV":
+			if value >= 7 {
+				delete(model.Stats, "ISV")
+			} else {
+				model.Stats["ISV"] = fmt.Sprintf("%d+", value)
+			}
+		case "W":
+			model.Stats["W"] = strconv.Itoa(value)
+			model.Wounds = value
+		case "MODELS":
+			model.Count = value
+		}
+	}
+}
+
+// Copy of the base defender with one grid point's characteristics applied
+func sweepDefender(base Unit, axes []SweepAxis, point []int) Unit {
+	defender := base.Clone()
+	defender.Abilities = append([]string(nil), base.Abilities...)
+	for i := range defender.Models {
+		stats := make(map[string]string, len(defender.Models[i].Stats))
+		for name, value := range defender.Models[i].Stats {
+			stats[name] = value
+		}
+		defender.Models[i].Stats = stats
+	}
+	for i, axis := range axes {
+		axis.apply(&defender, point[i])
+	}
+	return defender
+}
+
+// Every combination of axis values, varying the last axis fastest
+func sweepPoints(axes []SweepAxis) [][]int {
+	points := [][]int{{}}
+	for _, axis := range axes {
+		next := [][]int{}
+		for _, point := range points {
+			for _, value := range axis.Values {
+				next = append(next, append(append([]int(nil), point...), value))
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// Run each attacker against every point of the sweep grid, printing and writing each
+// weapon's mean damage per point
+func runSweep(attackerSpec, baseName string, axes []SweepAxis, scenario Scenario, workers int, seed int64) error {
+	attackers, err := selectUnits(attackerSpec)
+	if err != nil {
+		return err
+	}
+	if isReferenceTarget(baseName) {
+		if _, err := referenceTarget(baseName); err != nil {
+			return err
+		}
+	}
+	base := loadUnit(baseName)
+	points := sweepPoints(axes)
+	labels := matrixLabels(attackers)
+
+	for a, attackerFile := range attackers {
+		attacker := loadUnit(attackerFile)
+		weapons := (&UnitAttackSequence{Attacker: attacker, Scenario: scenario}).damageColumns()
+		fmt.Printf("=== Sweep: %s against %s (%d points) ===\n", labels[a], base.Name, len(points))
+
+		header := []string{}
+		for _, axis := range axes {
+			header = append(header, axis.Name)
+		}
+		header = append(header, weapons...)
+		header = append(header, "Total", "Destroyed %")
+
+		rows := [][]string{}
+		for _, point := range points {
+			conflict := UnitAttackSequence{
+				Attacker: attacker,
+				Defender: sweepDefender(base, axes, point),
+				Scenario: scenario,
+			}
+			results := runSimulations(conflict, _numSimulations, workers, seed, nil)
+
+			row := []string{}
+			for i, axis := range axes {
+				row = append(row, sweepValueLabel(axis.Name, point[i]))
+			}
+			summary := summariseMatchup(results, conflict.Attacker, conflict.Defender)
+			for _, weapon := range weapons {
+				total := 0
+				for _, result := range results {
+					total += result.DamageByLoadout[weapon]
+				}
+				row = append(row, fmt.Sprintf("%.2f", float64(total)/float64(len(results))))
+			}
+			row = append(row, fmt.Sprintf("%.2f", summary.MeanDamage), fmt.Sprintf("%.1f", 100*summary.KillChance))
+			rows = append(rows, row)
+		}
+
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(table, strings.Join(header, "\t")+"\t")
+		for _, row := range rows {
+			fmt.Fprintln(table, strings.Join(row, "\t")+"\t")
+		}
+		table.Flush()
+		fmt.Printf("\n")
+
+		filename := fmt.Sprintf("sweep_%s_vs_%s.csv", labels[a], base.Name)
+		if err := writeSweepCSV(filename, header, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Show ISV and FNP of 7 as "-" and saves as "N+"
+func sweepValueLabel(axis string, value int) string {
+	switch axis {
+	case "SV":
+		return fmt.Sprintf("%d+", value)
+	case "ISV", "FNP":
+		if value >= 7 {
+			return "-"
+		}
+		return fmt.Sprintf("%d+", value)
+	}
+	return strconv.Itoa(value)
+}
+
+func writeSweepCSV(filename string, header []string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
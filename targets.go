@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Prefix that selects a built-in reference target instead of a library file, e.g. "ref:meq"
+const _referencePrefix = "ref:"
+
+// ReferenceTarget is a typical defender profile that needs no library YAML
+type ReferenceTarget struct {
+	Name      string
+	Count     int
+	T         int
+	SV        int
+	ISV       int // 7 for none
+	W         int
+	LD        int
+	OC        int
+	Keywords  []string
+	Abilities []string
+}
+
+var _referenceTargets = map[string]ReferenceTarget{
+	"geq":           {"GEQ", 10, 3, 5, 7, 1, 7, 2, []string{"Infantry"}, nil},
+	"meq":           {"MEQ", 5, 4, 3, 7, 2, 6, 2, []string{"Infantry"}, nil},
+	"teq":           {"TEQ", 5, 5, 2, 4, 3, 6, 1, []string{"Infantry", "Terminator"}, nil},
+	"light_vehicle": {"Light Vehicle", 1, 9, 3, 7, 10, 6, 2, []string{"Vehicle"}, nil},
+	"heavy_vehicle": {"Heavy Vehicle", 1, 12, 2, 7, 16, 6, 5, []string{"Vehicle"}, nil},
+	"knight":        {"Knight", 1, 11, 3, 5, 22, 6, 10, []string{"Vehicle", "Titanic"}, nil},
+	"monster":       {"Monster", 1, 10, 2, 4, 10, 6, 3, []string{"Monster"}, nil},
+}
+
+// Reference target keys in a stable order
+func referenceTargetKeys() []string {
+	keys := []string{}
+	for key := range _referenceTargets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Check whether a unit name refers to a reference target rather than a library file
+func isReferenceTarget(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), _referencePrefix)
+}
+
+// Build the unit for a reference target name such as "ref:meq"
+func referenceTarget(name string) (Unit, error) {
+	key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.ToLower(name), _referencePrefix)))
+	target, exists := _referenceTargets[key]
+	if !exists {
+		return Unit{}, fmt.Errorf("unknown reference target '%s', expected one of %s", key, strings.Join(referenceTargetKeys(), ", "))
+	}
+
+	stats := map[string]string{
+		"T":  fmt.Sprintf("%d", target.T),
+		"SV": fmt.Sprintf("%d+", target.SV),
+		"W":  fmt.Sprintf("%d", target.W),
+		"LD": fmt.Sprintf("%d+", target.LD),
+		"OC": fmt.Sprintf("%d", target.OC),
+	}
+	if target.ISV < 7 {
+		stats["ISV"] = fmt.Sprintf("%d+", target.ISV)
+	}
+
+	unit := Unit{
+		Name:      target.Name,
+		Type:      "reference",
+		Keywords:  append([]string(nil), target.Keywords...),
+		Abilities: append([]string(nil), target.Abilities...),
+		Models:    []ModelData{{Name: target.Name, Count: target.Count, Stats: stats}},
+	}
+	return prepareUnit(unit, _referencePrefix+key), nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// TriggerEffect describes an extra effect fired by an unmodified hit or wound roll,
+// e.g. "Critical Hit: 1 Mortal Wound" or "Wound Roll 6+: +1 Damage"
+type TriggerEffect struct {
+	Source    string // Keyword or ability text the trigger was parsed from
+	Phase     string // "hit" or "wound"
+	Threshold int    // Unmodified roll needed, 0 means the weapon's critical threshold
+	Effect    string // "mortal", "hits", "damage" or "ap"
+	Value     string // Amount, may be dice notation like "D3"
+}
+
+// woundRoll is a single successful wound waiting for its save
+type woundRoll struct {
+	Devastating bool
+	BonusDamage int
+	BonusAP     int
+}
+
+var triggerPattern = regexp.MustCompile(`^(critical hits?|critical wounds?|hit rolls?(?: of)? (\d)\+?|wound rolls?(?: of)? (\d)\+?)\s*:\s*\+?\s*(\d*d?\d+)\s*(mortal wounds?|extra hits?|additional hits?|damage|ap)$`)
+
+// parseTriggerEffect parses trigger text, returning false if the text is not a trigger
+func parseTriggerEffect(text string) (TriggerEffect, bool) {
+	matches := triggerPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if matches == nil {
+		return TriggerEffect{}, false
+	}
+
+	trigger := TriggerEffect{Source: strings.TrimSpace(text), Value: matches[4]}
+
+	switch {
+	case strings.HasPrefix(matches[1], "critical hit"):
+		trigger.Phase = "hit"
+	case strings.HasPrefix(matches[1], "critical wound"):
+		trigger.Phase = "wound"
+	case matches[2] != "":
+		trigger.Phase = "hit"
+		trigger.Threshold, _ = strconv.Atoi(matches[2])
+	default:
+		trigger.Phase = "wound"
+		trigger.Threshold, _ = strconv.Atoi(matches[3])
+	}
+
+	switch {
+	case strings.HasPrefix(matches[5], "mortal"):
+		trigger.Effect = "mortal"
+	case strings.HasSuffix(matches[5], "hit") || strings.HasSuffix(matches[5], "hits"):
+		trigger.Effect = "hits"
+	default:
+		trigger.Effect = matches[5]
+	}
+
+	// Extra hits only make sense on hit rolls, damage and AP need a wound to attach to
+	if trigger.Effect == "hits" && trigger.Phase != "hit" {
+		return TriggerEffect{}, false
+	}
+	if (trigger.Effect == "damage" || trigger.Effect == "ap") && trigger.Phase != "wound" {
+		return TriggerEffect{}, false
+	}
+
+	return trigger, true
+}
+
+// Collect the trigger effects for a weapon from its keywords and the attacker's abilities
+func (conflict *UnitAttackSequence) weaponTriggers(weapon WeaponProfile, phase string) []TriggerEffect {
+	var triggers []TriggerEffect
+
+	sources := strings.Split(weapon.GetStringCharacteristic("Keywords"), ",")
+	sources = append(sources, conflict.Attacker.Abilities...)
+	for _, source := range sources {
+		if trigger, ok := parseTriggerEffect(source); ok && trigger.Phase == phase {
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers
+}
+
+// Check whether a trigger fires for an unmodified roll
+func (t TriggerEffect) fires(roll int, critical bool) bool {
+	if t.Threshold == 0 {
+		return critical
+	}
+	return roll >= t.Threshold
+}
+
+// Roll the trigger's value, defaulting to 1 if it cannot be parsed
+func (t TriggerEffect) amount(dice DiceSource) int {
+	value, err := rollExpression(dice, t.Value)
+	if err != nil {
+		return 1
+	}
+	return value
+}
+
+// Find the defender model group that currently receives allocated damage
+func (conflict *UnitAttackSequence) allocationTarget() int {
+	for i, model := range conflict.Defender.Models {
+		if model.Killed < model.Count {
+			return i
+		}
+	}
+	return -1
+}
+
+// Allocate mortal wounds one at a time so excess damage spills over to the next model
+func (conflict *UnitAttackSequence) allocateMortalWounds(mortalWounds int, source string) int {
+	damageApplied := 0
+	for i := 0; i < mortalWounds; i++ {
+		targetModelIndex := conflict.allocationTarget()
+		if targetModelIndex < 0 {
+			break
+		}
+		damageApplied += conflict.applyDamage(targetModelIndex, "1", "mortal")
+	}
+
+	if conflict.logger != nil && mortalWounds > 0 {
+		conflict.logger.Info("Mortal Wounds Allocated",
+			zap.String("source", source),
+			zap.Int("mortal_wounds", mortalWounds),
+			zap.Int("damage_applied", damageApplied))
+	}
+
+	return damageApplied
+}
@@ -16,10 +16,8 @@ const _unitLibraryFilepath = "./library/"
 const _heavyComments = true
 const _numSimulations = 1000
 
-// Global logger
-var combatLogger *zap.Logger
-
-func initLogger() {
+// Build the combat logger that writes a detailed trace of a simulation to combat_log.txt
+func newCombatLogger() *zap.Logger {
 	// Configure zap to write to a file
 	config := zap.Config{
 		Level:       zap.NewAtomicLevelAt(zap.InfoLevel),
@@ -42,22 +40,44 @@ func initLogger() {
 		ErrorOutputPaths: []string{"stderr"},
 	}
 
-	var err error
-	combatLogger, err = config.Build()
+	logger, err := config.Build()
 	if err != nil {
 		panic(err)
 	}
-}
-
-func closeLogger() {
-	if combatLogger != nil {
-		combatLogger.Sync()
-	}
+	return logger
 }
 
 type UnitAttackSequence struct {
 	Attacker Unit
 	Defender Unit
+	Scenario Scenario
+
+	// Each sequence owns its dice and logger so simulations can run concurrently. A nil
+	// logger disables the combat log.
+	dice   DiceSource
+	logger *zap.Logger
+
+	// Running totals of this sequence's own attacks
+	tally CombatTally
+
+	// Weapons with abilities applied, derived once per matchup from the unit templates
+	views        *weaponViews
+	reverseViews *weaponViews
+}
+
+// weaponView is a derived copy of a unit's weapons, indexed like Unit.Models
+type weaponView struct {
+	Loadouts         []map[string]WeaponProfile
+	UsesStratagem    bool // Attacking with this view spends the unit's stratagems
+	BattleShocksUser bool // A stratagem in the view, e.g. Red Rampage, Battle-shocks the unit
+
+	// Defender rules such as Stealth, applied per attack and never to the stored weapons
+	Defense DefensiveModifiers
+}
+
+type weaponViews struct {
+	withStratagems    *weaponView
+	withoutStratagems *weaponView
 }
 
 // New unit structure matching the library builder output
@@ -70,10 +90,15 @@ type Unit struct {
 	Keywords       []string        `yaml:"keywords,omitempty"`
 	Models         []ModelData     `yaml:"models"`
 	LoadoutOptions []LoadoutOption `yaml:"loadout_options,omitempty"`
+	Damaged        *DamagedProfile `yaml:"damaged,omitempty"`
+	FiringOrder    []string        `yaml:"firing_order,omitempty"` // Weapons, or "Model: Weapon" pairs, that fire in order
+	Size           *UnitSize       `yaml:"unit_size,omitempty"`    // Legal unit sizes, when known
 
 	// Internal tracking fields
 	ModelOrder    []string
 	UnitAbilities []string // For legacy compatibility
+	BattleShocked bool
+	StratagemUsed bool // Stratagems are used once per combat
 }
 
 type ModelData struct {
@@ -90,6 +115,25 @@ type ModelData struct {
 	CarryOverWounds int
 }
 
+// CombatTally counts the dice outcomes of a sequence's attacks
+type CombatTally struct {
+	Hits        int
+	Wounds      int // Including Lethal Hits and Devastating Wounds
+	Saves       int // Successful saves
+	FailedSaves int // Devastating Wounds are never saved and count as neither
+
+	DamageIgnored int // Prevented by Feel No Pain and damage reduction
+	Overkill      int // Damage beyond what the target model had left, or against models already destroyed
+}
+
+// UnitSize is the legal range of models in a unit. Model names the model group that grows or
+// shrinks, defaulting to the largest.
+type UnitSize struct {
+	Min   int    `yaml:"min"`
+	Max   int    `yaml:"max"`
+	Model string `yaml:"model,omitempty"`
+}
+
 type LoadoutOption struct {
 	Name        string   `yaml:"name"`
 	Type        string   `yaml:"type"`
@@ -117,7 +161,29 @@ type WeaponProfile struct {
 	}
 }
 
+// Compiled unit templates by file name, so each file is read and parsed only once
+var unitTemplates = map[string]Unit{}
+
+// Load a unit from the library, or a reference target such as "ref:meq", returning a
+// fresh copy of its compiled template
 func loadUnit(name string) Unit {
+	template, exists := unitTemplates[name]
+	if !exists {
+		if isReferenceTarget(name) {
+			var err error
+			if template, err = referenceTarget(name); err != nil {
+				panic(err)
+			}
+		} else {
+			template = compileUnit(name)
+		}
+		unitTemplates[name] = template
+	}
+	return template.Clone()
+}
+
+// Read and parse a unit file into a template
+func compileUnit(name string) Unit {
 	var (
 		data []byte
 		err  error
@@ -130,10 +196,15 @@ func loadUnit(name string) Unit {
 	if err = yaml.Unmarshal(data, &unit); err != nil {
 		panic(err)
 	}
+	return prepareUnit(unit, name)
+}
 
+// Initialize the internal fields of a freshly parsed unit
+func prepareUnit(unit Unit, name string) Unit {
 	// Initialize internal fields
 	unit.Source = name
 	unit.UnitAbilities = unit.Abilities // Copy abilities for legacy compatibility
+	unit.Damaged = parseDamagedProfile(unit)
 
 	// Process models and initialize tracking fields
 	unit.ModelOrder = make([]string, len(unit.Models))
@@ -210,7 +281,7 @@ func (conflict *UnitAttackSequence) applyDamage(modelIndex int, damString string
 		if strings.HasPrefix(diceStr, "d") {
 			diceStr = "1" + diceStr // Convert "D6" to "1D6"
 		}
-		if damage, err = rollAndAdd(diceStr); err != nil {
+		if damage, err = rollAndAdd(conflict.dice, diceStr); err != nil {
 			fmt.Printf("Error rolling damage dice '%s': %v\n", diceStr, err)
 			damage = 1 // Default to 1 damage on error
 		}
@@ -222,6 +293,13 @@ func (conflict *UnitAttackSequence) applyDamage(modelIndex int, damString string
 		}
 	}
 
+	// Add any bonus damage from on-wound triggers
+	if exists, bonus := stringExistsInSlice("bonus damage", params); exists {
+		if bonusDamage, err := strconv.Atoi(bonus); err == nil {
+			damage += bonusDamage
+		}
+	}
+
 	if modelIndex >= len(conflict.Defender.Models) {
 		return 0
 	}
@@ -232,52 +310,70 @@ func (conflict *UnitAttackSequence) applyDamage(modelIndex int, damString string
 	fnp := 0
 	mwfnp := 0
 
-	// Check unit abilities for FNP
+	// Check unit abilities for FNP, "against mortal wounds" variants only apply to mortals
 	for _, ability := range conflict.Defender.Abilities {
-		if strings.Contains(strings.ToLower(ability), "feel no pain") {
+		abilityLower := strings.ToLower(ability)
+		if strings.Contains(abilityLower, "feel no pain") {
 			// Try to extract FNP value - this would need more sophisticated parsing
 			// For now, assume common values
+			value := 0
 			if strings.Contains(ability, "5+") {
-				fnp = 5
+				value = 5
 			} else if strings.Contains(ability, "6+") {
-				fnp = 6
+				value = 6
 			} else if strings.Contains(ability, "4+") {
-				fnp = 4
+				value = 4
+			}
+
+			if strings.Contains(abilityLower, "mortal") {
+				mwfnp = value
+			} else {
+				fnp = value
 			}
 		}
 	}
 
 	if fnp > 0 || (mortals && mwfnp > 0) {
-		threshold := 0
-		if fnp > threshold {
-			threshold = fnp
-		}
-		if mortals && mwfnp > threshold {
+		// Use the best (lowest) FNP that applies to this damage
+		threshold := fnp
+		if mortals && mwfnp > 0 && (threshold == 0 || mwfnp < threshold) {
 			threshold = mwfnp
 		}
 
 		initialDamage := damage
 		for i := 0; i < initialDamage; i++ {
-			roll := rollDice(1, 6)
+			roll := rollDice(conflict.dice, 1, 6)
 			if roll >= threshold {
 				damage = damage - 1
+				conflict.tally.DamageIgnored++
 			}
 		}
 	}
 
 	// Check for special abilities like NECRODERMIS
 	if abilityCheck("NECRODERMIS", conflict.Defender.UnitAbilities) {
-		damage = int(math.Ceil(float64(damage) / 2))
+		halved := int(math.Ceil(float64(damage) / 2))
+		conflict.tally.DamageIgnored += damage - halved
+		damage = halved
 	}
 
 	// Calculate remaining health before applying damage
 	remainingHealth := model.Wounds - model.CarryOverWounds
 	aliveModels := model.Count - model.Killed
 
+	// Damage past the model's remaining wounds is lost rather than carried to the next model
+	if aliveModels <= 0 {
+		conflict.tally.Overkill += damage
+	} else if damage > remainingHealth {
+		conflict.tally.Overkill += damage - remainingHealth
+	}
+
+	destroyed := false
 	model.CarryOverWounds = model.CarryOverWounds + damage
 	if model.CarryOverWounds >= model.Wounds && model.Killed < model.Count {
 		model.Killed++
 		model.CarryOverWounds = 0
+		destroyed = true
 	}
 
 	// Calculate new remaining health
@@ -285,8 +381,8 @@ func (conflict *UnitAttackSequence) applyDamage(modelIndex int, damString string
 	newAliveModels := model.Count - model.Killed
 
 	// Log health changes
-	if combatLogger != nil {
-		combatLogger.Info("Damage Applied",
+	if conflict.logger != nil {
+		conflict.logger.Info("Damage Applied",
 			zap.Int("damage_amount", damage),
 			zap.String("damage_characteristic", damString),
 			zap.Int("target_model_index", modelIndex),
@@ -304,21 +400,25 @@ func (conflict *UnitAttackSequence) applyDamage(modelIndex int, damString string
 	// Update the model in the slice
 	conflict.Defender.Models[modelIndex] = *model
 
+	// Raise the destruction event for Deadly Demise, Fights on Death and similar rules
+	if destroyed {
+		conflict.onModelDestroyed(modelIndex, params)
+	}
+
 	return damage
 }
 
-func (u *Unit) Reload() {
-	reloadUnit := loadUnit(u.Source)
-	for i := range u.Models {
-		u.Models[i].Killed = 0
-		u.Models[i].CarryOverWounds = 0
-		// Restore original counts and stats
-		u.Models[i].Count = reloadUnit.Models[i].Count
-		u.Models[i].Wounds = reloadUnit.Models[i].Wounds
-	}
+// Copy the unit's mutable combat state (kills, carry-over wounds, flags). Stats, abilities
+// and loadouts are never modified during combat, so they are shared with the original.
+func (u Unit) Clone() Unit {
+	u.Models = append([]ModelData(nil), u.Models...)
+	return u
 }
 
+// Reset the combat state in place for the next simulation
 func (u *Unit) Reset() {
+	u.BattleShocked = false
+	u.StratagemUsed = false
 	for i := range u.Models {
 		u.Models[i].Killed = 0
 		u.Models[i].CarryOverWounds = 0
@@ -379,6 +479,11 @@ func (w *WeaponProfile) GetIntCharacteristic(name string) int {
 	return 0
 }
 
+// Helper function to get a save characteristic like "3+" as an int, 7 meaning no save
+func (m *ModelData) GetSaveCharacteristic(name string) int {
+	return parseRollThreshold(m.Stats[name])
+}
+
 // Helper function to get string characteristic
 func (w *WeaponProfile) GetStringCharacteristic(name string) string {
 	if value, exists := w.Characteristics[name]; exists {
@@ -389,13 +494,19 @@ func (w *WeaponProfile) GetStringCharacteristic(name string) string {
 
 // Enhanced loadout attack method that includes wound rolling and saves
 func (conflict *UnitAttackSequence) loadoutAttackSequence() (map[string]int, int) {
-	// Apply abilities and weapon modifications at start of combat
-	conflict.applyAbilities()
+	// Use the attacker's weapons with abilities applied, leaving the loaded unit untouched
+	view := conflict.attackerWeapons()
+	if view.UsesStratagem {
+		conflict.Attacker.StratagemUsed = true
+	}
+	if view.BattleShocksUser {
+		conflict.Attacker.BattleShocked = true
+	}
 
 	totalDamage := 0
 	damageByLoadout := make(map[string]int)
 
-	// Initialize damage tracking for all weapons to 0
+	// Initialize damage tracking for all weapons and mortal wound actions to 0
 	for _, model := range conflict.Attacker.Models {
 		if model.Loadouts != nil {
 			for weaponName := range model.Loadouts {
@@ -403,384 +514,476 @@ func (conflict *UnitAttackSequence) loadoutAttackSequence() (map[string]int, int
 			}
 		}
 	}
-
-	// Find the first alive model in the defender for targeting
-	var targetModel *ModelData
-	targetModelIndex := -1
-	for i, model := range conflict.Defender.Models {
-		if model.Killed < model.Count {
-			targetModel = &conflict.Defender.Models[i]
-			targetModelIndex = i
-			break
-		}
+	for _, action := range conflict.Scenario.MortalWounds {
+		damageByLoadout[action.Name] = 0
 	}
 
-	if targetModel == nil {
-		if combatLogger != nil {
-			combatLogger.Info("No alive models to target")
+	// Find the first alive model in the defender for targeting
+	targetModelIndex := conflict.allocationTarget()
+	if targetModelIndex < 0 {
+		if conflict.logger != nil {
+			conflict.logger.Info("No alive models to target")
 		}
 		return damageByLoadout, 0
 	}
+	targetModel := &conflict.Defender.Models[targetModelIndex]
 
-	if combatLogger != nil {
-		combatLogger.Info("########################################")
-		combatLogger.Info(fmt.Sprintf("Targeting model: %s (T%s, SV%s, ISV%s)",
+	if conflict.logger != nil {
+		conflict.logger.Info("########################################")
+		conflict.logger.Info(fmt.Sprintf("Targeting model: %s (T%s, SV%s, ISV%s)",
 			targetModel.Name,
 			targetModel.Stats["T"],
 			targetModel.Stats["SV"],
 			targetModel.Stats["ISV"]))
 	}
 
-	// Iterate through all models in the attacker
-	for _, model := range conflict.Attacker.Models {
-		// Skip killed models
-		if model.Killed >= model.Count {
+	// Defender rules such as Stealth are applied per attack, never to the stored weapons
+	defense := view.Defense
+	if conflict.logger != nil && len(defense.Sources) > 0 {
+		conflict.logger.Info("Defensive Modifiers",
+			zap.Strings("sources", defense.Sources),
+			zap.Int("ranged_hit_mod", defense.RangedHitMod),
+			zap.Int("melee_hit_mod", defense.MeleeHitMod),
+			zap.Int("wound_mod", defense.WoundMod),
+			zap.Int("wound_mod_strength_above_toughness", defense.WoundModStrong),
+			zap.Bool("no_hit_rerolls", defense.NoHitRerolls),
+			zap.Bool("no_wound_rerolls", defense.NoWoundRerolls),
+			zap.Int("min_wound_roll", defense.MinWoundRoll))
+	}
+
+	// Resolve mortal wound actions from the scenario before any weapon attacks
+	for _, action := range conflict.Scenario.MortalWounds {
+		damageApplied := conflict.resolveMortalWoundAction(action)
+		totalDamage += damageApplied
+		damageByLoadout[action.Name] += damageApplied
+	}
+
+	// Model groups fire their weapons in the unit's explicit or default firing order
+	steps := conflict.Attacker.firingSteps()
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Firing Order: %s", strings.Join(conflict.Attacker.stepLabels(steps), ", ")))
+	}
+
+	for _, step := range steps {
+		modelIndex, weaponName := step.Group, step.Weapon
+		weapon, exists := view.Loadouts[modelIndex][weaponName]
+		if !exists {
 			continue
 		}
+		weapon = defense.applyToWeapon(weapon)
 
-		// Calculate how many of this model are still alive
+		// Read the model group as it is now, as Deadly Demise or Fights on Death from an
+		// earlier weapon may have destroyed or wounded some of it
+		model := conflict.Attacker.Models[modelIndex]
 		aliveCount := model.Count - model.Killed
+		if aliveCount <= 0 {
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Skipping %s: every %s has been destroyed", weaponName, model.Name))
+			}
+			continue
+		}
 
-		// Iterate through each loadout/weapon for this model
-		if model.Loadouts != nil {
-			// First, check if this model has loadout options
-			var weaponsToUse []string
-			if len(conflict.Attacker.LoadoutOptions) > 0 {
-				// If there are loadout options, use those
-				for _, option := range conflict.Attacker.LoadoutOptions {
-					if option.Type == "group" {
-						weaponsToUse = option.Options
-						break
-					}
-				}
+		// A model in its damaged bracket attacks with a degraded profile
+		damaged := conflict.Attacker.isDamaged(model)
+		if damaged && conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("Damaged Profile: %s has %d wounds remaining (%s)",
+				model.Name,
+				model.Wounds-model.CarryOverWounds,
+				conflict.Attacker.Damaged.String()))
+		}
+
+		// Move on to the next model group once the current target is wiped out
+		if targetModel.Killed >= targetModel.Count {
+			if targetModelIndex = conflict.allocationTarget(); targetModelIndex < 0 {
+				break
 			}
+			targetModel = &conflict.Defender.Models[targetModelIndex]
+		}
 
-			// If no loadout options found, use all weapons
-			if len(weaponsToUse) == 0 {
-				for weaponName := range model.Loadouts {
-					weaponsToUse = append(weaponsToUse, weaponName)
-				}
+		// Only Indirect Fire weapons can shoot a target that is not visible
+		indirect := conflict.isIndirectFire(weapon)
+		if conflict.Scenario.NotVisible && !indirect && strings.Contains(strings.ToLower(weapon.Type), "ranged") {
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Skipping %s: target not visible and weapon lacks Indirect Fire", weaponName))
 			}
+			continue
+		}
 
-			// Now process each weapon in the loadout
-			for _, weaponName := range weaponsToUse {
-				weapon, exists := model.Loadouts[weaponName]
-				if !exists {
-					continue
-				}
+		// Add visual separator for new weapon attack
+		if conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("Starting attack with %s (%s)", weaponName, model.Name))
+			conflict.logger.Info("vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv")
+		}
 
-				// Add visual separator for new weapon attack
-				if combatLogger != nil {
-					combatLogger.Info(fmt.Sprintf("Starting attack with %s (%s)", weaponName, model.Name))
-					combatLogger.Info("vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv")
-				}
+		if conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("Starting weapon attack: %s (%s) targeting %s",
+				weaponName,
+				weapon.Type,
+				targetModel.Name))
 
-				if combatLogger != nil {
-					combatLogger.Info(fmt.Sprintf("Starting weapon attack: %s (%s) targeting %s",
-						weaponName,
-						weapon.Type,
-						targetModel.Name))
+			if conflict.Scenario.Cover || conflict.Scenario.Terrain != "" || indirect {
+				terrain := conflict.Scenario.Terrain
+				if terrain == "" {
+					terrain = "open"
 				}
+				conflict.logger.Info(fmt.Sprintf("Cover Check: %s in %s terrain, Benefit of Cover against %s: %v",
+					conflict.Defender.Name,
+					terrain,
+					weaponName,
+					conflict.hasBenefitOfCover(weapon)))
+			}
+		}
 
-				// Get number of attacks
-				attacksStr := weapon.GetStringCharacteristic("A")
-				attacks := 0
-
-				// Handle different attack formats
-				if attacksStr != "" {
-					if attacksInt, err := strconv.Atoi(attacksStr); err == nil {
-						attacks = attacksInt
-					} else {
-						// Handle dice notation like "D6", "2D6", etc.
-						diceStr := strings.ToLower(attacksStr)
-						if strings.HasPrefix(diceStr, "d") {
-							diceStr = "1" + diceStr
-						}
-						if attacksDice, err := rollAndAdd(diceStr); err == nil {
-							attacks = attacksDice
-						} else {
-							if combatLogger != nil {
-								combatLogger.Warn(fmt.Sprintf("Could not parse attacks '%s': %v", attacksStr, err))
-							}
-							continue
-						}
-					}
+		// Get number of attacks
+		attacksStr := weapon.GetStringCharacteristic("A")
+		attacks := 0
+
+		// Handle different attack formats
+		if attacksStr != "" {
+			if attacksInt, err := strconv.Atoi(attacksStr); err == nil {
+				attacks = attacksInt
+			} else {
+				// Handle dice notation like "D6", "2D6", etc.
+				diceStr := strings.ToLower(attacksStr)
+				if strings.HasPrefix(diceStr, "d") {
+					diceStr = "1" + diceStr
+				}
+				if attacksDice, err := rollAndAdd(conflict.dice, diceStr); err == nil {
+					attacks = attacksDice
 				} else {
-					if combatLogger != nil {
-						combatLogger.Warn("No attacks found for weapon, skipping")
+					if conflict.logger != nil {
+						conflict.logger.Warn(fmt.Sprintf("Could not parse attacks '%s': %v", attacksStr, err))
 					}
 					continue
 				}
+			}
+		} else {
+			if conflict.logger != nil {
+				conflict.logger.Warn("No attacks found for weapon, skipping")
+			}
+			continue
+		}
 
-				// Calculate total attacks (attacks per weapon * number of alive models)
-				totalAttacks := attacks * aliveCount
+		// Apply the damaged profile to this attack only, leaving the stored weapon untouched
+		if damaged {
+			weapon.Modifiers.HitMod += conflict.Attacker.Damaged.HitMod
+			weapon.Modifiers.WoundMod += conflict.Attacker.Damaged.WoundMod
+			attacks += conflict.Attacker.Damaged.AttacksMod
+			if attacks < 1 {
+				attacks = 1
+			}
+		}
 
-				// Log attack count
-				if combatLogger != nil {
-					combatLogger.Info(fmt.Sprintf("Attack Count: %s - %s attacks, %d alive models, %d total attacks",
-						weaponName,
-						attacksStr,
-						aliveCount,
-						totalAttacks))
-				}
+		// Calculate total attacks (attacks per weapon * number of alive models)
+		totalAttacks := attacks * aliveCount
 
-				// PHASE 1: Roll for hits
-				hits := 0
-				sustainedHits := 0
-				lethalHits := 0
+		// Log attack count
+		if conflict.logger != nil {
+			conflict.logger.Info(fmt.Sprintf("Attack Count: %s - %s attacks, %d alive models, %d total attacks",
+				weaponName,
+				attacksStr,
+				aliveCount,
+				totalAttacks))
+		}
 
-				// Check if weapon has Torrent (auto-hit)
-				keywords := weapon.GetStringCharacteristic("Keywords")
-				isTorrent := strings.Contains(strings.ToLower(keywords), "torrent")
+		// PHASE 1: Roll for hits
+		hits := 0
+		sustainedHits := 0
+		lethalHits := 0
+		mortalWounds := 0
+		hitTriggers := conflict.weaponTriggers(weapon, "hit")
+
+		// Check if weapon has Torrent (auto-hit)
+		keywords := weapon.GetStringCharacteristic("Keywords")
+		isTorrent := strings.Contains(strings.ToLower(keywords), "torrent")
+
+		if isTorrent {
+			// Torrent weapons auto-hit
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Hit Phase - Torrent: %d attacks auto-hit", totalAttacks))
+			}
+			hits = totalAttacks
+		} else {
+			// Get skill value (BS for ranged, WS for melee)
+			var skillStr string
+			if strings.Contains(strings.ToLower(weapon.Type), "ranged") {
+				skillStr = weapon.GetStringCharacteristic("BS")
+			} else if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+				skillStr = weapon.GetStringCharacteristic("WS")
+			} else {
+				if conflict.logger != nil {
+					conflict.logger.Warn(fmt.Sprintf("Unknown weapon type '%s', assuming ranged", weapon.Type))
+				}
+				skillStr = weapon.GetStringCharacteristic("BS")
+			}
 
-				if isTorrent {
-					// Torrent weapons auto-hit
-					if combatLogger != nil {
-						combatLogger.Info(fmt.Sprintf("Hit Phase - Torrent: %d attacks auto-hit", totalAttacks))
-					}
-					hits = totalAttacks
+			// Convert skill from "3+" to 3
+			skillValue := 0
+			if skillStr != "" {
+				skillStr = strings.TrimSpace(skillStr)
+				skillStr = strings.Replace(skillStr, "+", "", -1)
+				if skill, err := strconv.Atoi(skillStr); err == nil {
+					skillValue = skill
 				} else {
-					// Get skill value (BS for ranged, WS for melee)
-					var skillStr string
-					if strings.Contains(strings.ToLower(weapon.Type), "ranged") {
-						skillStr = weapon.GetStringCharacteristic("BS")
-					} else if strings.Contains(strings.ToLower(weapon.Type), "melee") {
-						skillStr = weapon.GetStringCharacteristic("WS")
-					} else {
-						if combatLogger != nil {
-							combatLogger.Warn(fmt.Sprintf("Unknown weapon type '%s', assuming ranged", weapon.Type))
-						}
-						skillStr = weapon.GetStringCharacteristic("BS")
+					if conflict.logger != nil {
+						conflict.logger.Warn(fmt.Sprintf("Could not parse skill '%s': %v", skillStr, err))
 					}
+					continue
+				}
+			} else {
+				if conflict.logger != nil {
+					conflict.logger.Warn("No skill value found for weapon, skipping")
+				}
+				continue
+			}
 
-					// Convert skill from "3+" to 3
-					skillValue := 0
-					if skillStr != "" {
-						skillStr = strings.TrimSpace(skillStr)
-						skillStr = strings.Replace(skillStr, "+", "", -1)
-						if skill, err := strconv.Atoi(skillStr); err == nil {
-							skillValue = skill
-						} else {
-							if combatLogger != nil {
-								combatLogger.Warn(fmt.Sprintf("Could not parse skill '%s': %v", skillStr, err))
-							}
-							continue
-						}
-					} else {
-						if combatLogger != nil {
-							combatLogger.Warn("No skill value found for weapon, skipping")
-						}
-						continue
-					}
+			// Indirect Fire at a target that is not visible: -1 to hit and unmodified 1-3 always fail
+			autoFail := 1
+			if indirect {
+				weapon.Modifiers.HitMod -= 1
+				autoFail = 3
+				if conflict.logger != nil {
+					conflict.logger.Info(fmt.Sprintf("Indirect Fire: %s shooting at a target that is not visible, -1 to hit and unmodified rolls of 1-%d fail",
+						weaponName,
+						autoFail))
+				}
+			}
 
-					// Add hit modifier
-					finalSkill := skillValue - weapon.Modifiers.HitMod
-					if finalSkill < 2 {
-						finalSkill = 2 // Minimum hit on 2+
-					}
-					if finalSkill > 6 {
-						finalSkill = 6 // Maximum hit on 6+
-					}
+			// Add hit modifier, capped at +1 or -1 once every source is combined
+			hitMod := capRollModifier(weapon.Modifiers.HitMod)
+			finalSkill := skillValue - hitMod
+			if finalSkill < 2 {
+				finalSkill = 2 // Minimum hit on 2+
+			}
+			if finalSkill > 6 {
+				finalSkill = 6 // Maximum hit on 6+
+			}
+
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Hit Phase - Rolling: %d attacks, need %d+ to hit (base %d+ with %+d modifier)",
+					totalAttacks,
+					finalSkill,
+					skillValue,
+					hitMod))
+			}
 
-					if combatLogger != nil {
-						combatLogger.Info(fmt.Sprintf("Hit Phase - Rolling: %d attacks, need %d+ to hit (base %d+ with %+d modifier)",
-							totalAttacks,
-							finalSkill,
-							skillValue,
-							weapon.Modifiers.HitMod))
+			// Roll for hits individually
+			for i := 0; i < totalAttacks; i++ {
+				roll := rollDice(conflict.dice, 1, 6)
+				hit := roll >= finalSkill && roll > autoFail
+				criticalHit := roll >= weapon.Modifiers.CritHit
+				rerolled := false
+
+				// Log initial hit roll
+				if conflict.logger != nil {
+					conflict.logger.Info(fmt.Sprintf("Initial Hit Roll: Attack %d rolled %d (need %d+), hit: %v, critical: %v",
+						i+1,
+						roll,
+						finalSkill,
+						hit,
+						criticalHit))
+				}
+
+				// Handle rerolls for misses
+				if !hit {
+					shouldReroll := false
+					if weapon.Modifiers.RerollHits {
+						shouldReroll = true
+					} else if weapon.Modifiers.RerollHit1s && roll == 1 {
+						shouldReroll = true
 					}
 
-					// Roll for hits individually
-					for i := 0; i < totalAttacks; i++ {
-						roll := rollDice(1, 6)
-						hit := roll >= finalSkill
-						criticalHit := roll >= weapon.Modifiers.CritHit
-						rerolled := false
+					if shouldReroll {
+						rerollResult := rollDice(conflict.dice, 1, 6)
+						hit = rerollResult >= finalSkill && rerollResult > autoFail
+						criticalHit = rerollResult >= weapon.Modifiers.CritHit
+						rerolled = true
 
-						// Log initial hit roll
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Initial Hit Roll: Attack %d rolled %d (need %d+), hit: %v, critical: %v",
+						if conflict.logger != nil {
+							conflict.logger.Info(fmt.Sprintf("Miss Reroll: Attack %d rerolled %d (original %d), hit: %v, critical: %v",
 								i+1,
+								rerollResult,
 								roll,
-								finalSkill,
 								hit,
 								criticalHit))
 						}
 
-						// Handle rerolls for misses
-						if !hit {
-							shouldReroll := false
-							if weapon.Modifiers.RerollHits {
-								shouldReroll = true
-							} else if weapon.Modifiers.RerollHit1s && roll == 1 {
-								shouldReroll = true
-							}
-
-							if shouldReroll {
-								rerollResult := rollDice(1, 6)
-								hit = rerollResult >= finalSkill
-								criticalHit = rerollResult >= weapon.Modifiers.CritHit
-								rerolled = true
-
-								if combatLogger != nil {
-									combatLogger.Info(fmt.Sprintf("Miss Reroll: Attack %d rerolled %d (original %d), hit: %v, critical: %v",
-										i+1,
-										rerollResult,
-										roll,
-										hit,
-										criticalHit))
-								}
-
-								roll = rerollResult // Update roll for logging
-							}
-						} else if weapon.Modifiers.CritHitFish && !criticalHit {
-							// Critical hit fishing: reroll successful hits that weren't critical
-							rerollResult := rollDice(1, 6)
-							hit = rerollResult >= finalSkill
-							criticalHit = rerollResult >= weapon.Modifiers.CritHit
-							rerolled = true
-
-							if combatLogger != nil {
-								combatLogger.Info(fmt.Sprintf("Critical Hit Fishing Reroll: Attack %d rerolled %d (original %d), hit: %v, critical: %v",
-									i+1,
-									rerollResult,
-									roll,
-									hit,
-									criticalHit))
-							}
+						roll = rerollResult // Update roll for logging
+					}
+				} else if weapon.Modifiers.CritHitFish && !criticalHit {
+					// Critical hit fishing: reroll successful hits that weren't critical
+					rerollResult := rollDice(conflict.dice, 1, 6)
+					hit = rerollResult >= finalSkill && rerollResult > autoFail
+					criticalHit = rerollResult >= weapon.Modifiers.CritHit
+					rerolled = true
+
+					if conflict.logger != nil {
+						conflict.logger.Info(fmt.Sprintf("Critical Hit Fishing Reroll: Attack %d rerolled %d (original %d), hit: %v, critical: %v",
+							i+1,
+							rerollResult,
+							roll,
+							hit,
+							criticalHit))
+					}
 
-							roll = rerollResult // Update roll for logging
-						}
+					roll = rerollResult // Update roll for logging
+				}
 
-						if hit {
-							hits++
-
-							// Handle critical hit effects
-							if criticalHit {
-								// Check for Sustained Hits
-								keywordsLower := strings.ToLower(keywords)
-								if strings.Contains(keywordsLower, "sustained hits") {
-									// Parse sustained hits value
-									// First split by commas, then by spaces
-									keywordGroups := strings.Split(keywordsLower, ",")
-									for _, group := range keywordGroups {
-										group = strings.TrimSpace(group)
-										if strings.Contains(group, "sustained hits") {
-											// Split the group into words
-											keywordParts := strings.Fields(group)
-											for j, part := range keywordParts {
-												if part == "sustained" && j+2 < len(keywordParts) && keywordParts[j+1] == "hits" {
-													// Handle both formats: "Sustained Hits 1" and "Sustained Hits D3"
-													sustainedValue := 1 // Default to 1 if not specified
-													if j+2 < len(keywordParts) {
-														sustainedStr := strings.TrimSpace(keywordParts[j+2])
-														if strings.HasPrefix(sustainedStr, "d") {
-															// Handle dice notation
-															if sustainedDice, err := rollAndAdd(sustainedStr); err == nil {
-																sustainedValue = sustainedDice
-															}
-														} else if val, err := strconv.Atoi(sustainedStr); err == nil {
-															sustainedValue = val
-														} else {
-															if combatLogger != nil {
-																combatLogger.Warn(fmt.Sprintf("Could not parse Sustained Hits value '%s'", sustainedStr))
-															}
-														}
+				if hit {
+					hits++
+
+					// Handle critical hit effects
+					if criticalHit {
+						// Check for Sustained Hits
+						keywordsLower := strings.ToLower(keywords)
+						if strings.Contains(keywordsLower, "sustained hits") {
+							// Parse sustained hits value
+							// First split by commas, then by spaces
+							keywordGroups := strings.Split(keywordsLower, ",")
+							for _, group := range keywordGroups {
+								group = strings.TrimSpace(group)
+								if strings.Contains(group, "sustained hits") {
+									// Split the group into words
+									keywordParts := strings.Fields(group)
+									for j, part := range keywordParts {
+										if part == "sustained" && j+2 < len(keywordParts) && keywordParts[j+1] == "hits" {
+											// Handle both formats: "Sustained Hits 1" and "Sustained Hits D3"
+											sustainedValue := 1 // Default to 1 if not specified
+											if j+2 < len(keywordParts) {
+												sustainedStr := strings.TrimSpace(keywordParts[j+2])
+												if strings.HasPrefix(sustainedStr, "d") {
+													// Handle dice notation
+													if sustainedDice, err := rollAndAdd(conflict.dice, sustainedStr); err == nil {
+														sustainedValue = sustainedDice
 													}
-
-													if combatLogger != nil {
-														combatLogger.Info(fmt.Sprintf("Sustained Hits Found: Weapon has 'Sustained Hits %d' keyword (from '%s')",
-															sustainedValue,
-															keywords))
+												} else if val, err := strconv.Atoi(sustainedStr); err == nil {
+													sustainedValue = val
+												} else {
+													if conflict.logger != nil {
+														conflict.logger.Warn(fmt.Sprintf("Could not parse Sustained Hits value '%s'", sustainedStr))
 													}
+												}
+											}
 
-													sustainedHits += sustainedValue
-													hits += sustainedValue
+											if conflict.logger != nil {
+												conflict.logger.Info(fmt.Sprintf("Sustained Hits Found: Weapon has 'Sustained Hits %d' keyword (from '%s')",
+													sustainedValue,
+													keywords))
+											}
 
-													if combatLogger != nil {
-														combatLogger.Info(fmt.Sprintf("Sustained Hits Applied: Critical hit roll %d generated %d additional hits (total hits: %d)",
-															roll,
-															sustainedValue,
-															hits))
-													}
-													break
-												}
+											sustainedHits += sustainedValue
+											hits += sustainedValue
+
+											if conflict.logger != nil {
+												conflict.logger.Info(fmt.Sprintf("Sustained Hits Applied: Critical hit roll %d generated %d additional hits (total hits: %d)",
+													roll,
+													sustainedValue,
+													hits))
 											}
+											break
 										}
 									}
 								}
-
-								// Check for Lethal Hits
-								if strings.Contains(keywordsLower, "lethal hits") {
-									lethalHits++
-								}
 							}
 						}
 
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Hit Roll Result: Attack %d final roll %d (need %d+), hit: %v, critical: %v, rerolled: %v, running hits: %d",
-								i+1,
-								roll,
-								finalSkill,
-								hit,
-								criticalHit,
-								rerolled,
-								hits))
+						// Check for Lethal Hits
+						if strings.Contains(keywordsLower, "lethal hits") {
+							lethalHits++
 						}
 					}
 
-					if combatLogger != nil {
-						combatLogger.Info(fmt.Sprintf("Hit Phase Complete: %d hits (including %d Sustained Hits, %d Lethal Hits)",
-							hits,
-							sustainedHits,
-							lethalHits))
+					// Fire on-hit triggers such as extra mortal wounds or extra hits
+					for _, trigger := range hitTriggers {
+						if !trigger.fires(roll, criticalHit) {
+							continue
+						}
+						amount := trigger.amount(conflict.dice)
+						switch trigger.Effect {
+						case "mortal":
+							mortalWounds += amount
+						case "hits":
+							hits += amount
+						}
+
+						if conflict.logger != nil {
+							conflict.logger.Info(fmt.Sprintf("Hit Trigger Fired: '%s' on roll %d (%s +%d)",
+								trigger.Source,
+								roll,
+								trigger.Effect,
+								amount))
+						}
 					}
 				}
 
-				// PHASE 2: Roll for wounds
-				wounds, criticalWounds := conflict.rollWounds(hits, weapon, targetModel, lethalHits)
-
-				// PHASE 3: Roll for saves and apply damage
-				damageApplied := 0
-				if wounds > 0 {
-					damageApplied = conflict.rollSaves(wounds, criticalWounds, weapon, targetModel, targetModelIndex)
-					totalDamage += damageApplied
-					damageByLoadout[weaponName] = damageApplied
+				if conflict.logger != nil {
+					conflict.logger.Info(fmt.Sprintf("Hit Roll Result: Attack %d final roll %d (need %d+), hit: %v, critical: %v, rerolled: %v, running hits: %d",
+						i+1,
+						roll,
+						finalSkill,
+						hit,
+						criticalHit,
+						rerolled,
+						hits))
 				}
+			}
 
-				// Log remaining defenders after damage
-				if combatLogger != nil {
-					remainingModels := 0
-					totalWoundsRemaining := 0
-					for _, defModel := range conflict.Defender.Models {
-						alive := defModel.Count - defModel.Killed
-						if alive > 0 {
-							remainingModels += alive
-							totalWoundsRemaining += alive * defModel.Wounds
-						}
-					}
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Hit Phase Complete: %d hits (including %d Sustained Hits, %d Lethal Hits)",
+					hits,
+					sustainedHits,
+					lethalHits))
+			}
+		}
+
+		// PHASE 2: Roll for wounds
+		conflict.tally.Hits += hits
+		wounds, woundMortals := conflict.rollWounds(hits, weapon, defense, targetModel, lethalHits)
+		mortalWounds += woundMortals
+		conflict.tally.Wounds += len(wounds)
+
+		// PHASE 3: Roll for saves and apply damage
+		damageApplied := 0
+		if len(wounds) > 0 {
+			damageApplied = conflict.rollSaves(wounds, weapon, targetModel, targetModelIndex)
+		}
 
-					combatLogger.Info(fmt.Sprintf("Weapon Attack Complete: Applied %d damage (cumulative: %d), %d models remaining with %d wounds",
-						damageApplied,
-						totalDamage,
-						remainingModels,
-						totalWoundsRemaining))
+		// PHASE 4: Mortal wounds from triggers are allocated after the attack's normal damage
+		damageApplied += conflict.allocateMortalWounds(mortalWounds, weaponName)
+		totalDamage += damageApplied
+		damageByLoadout[weaponName] += damageApplied
+
+		// Log remaining defenders after damage
+		if conflict.logger != nil {
+			remainingModels := 0
+			totalWoundsRemaining := 0
+			for _, defModel := range conflict.Defender.Models {
+				alive := defModel.Count - defModel.Killed
+				if alive > 0 {
+					remainingModels += alive
+					totalWoundsRemaining += alive * defModel.Wounds
 				}
 			}
+
+			conflict.logger.Info(fmt.Sprintf("Weapon Attack Complete: Applied %d damage (cumulative: %d), %d models remaining with %d wounds",
+				damageApplied,
+				totalDamage,
+				remainingModels,
+				totalWoundsRemaining))
 		}
 	}
 
-	if combatLogger != nil {
-		combatLogger.Info(fmt.Sprintf("Combat Complete: Total damage %d",
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Combat Complete: Total damage %d",
 			totalDamage))
 	}
 	return damageByLoadout, totalDamage
 }
 
 // Wound rolling method with detailed logging for each roll
-func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, targetModel *ModelData, lethalHits int) (int, int) {
+func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, defense DefensiveModifiers, targetModel *ModelData, lethalHits int) ([]woundRoll, int) {
 	if hits <= 0 {
-		return 0, 0
+		return nil, 0
 	}
 
 	// Get weapon strength
@@ -790,18 +993,18 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 		if s, err := strconv.Atoi(strengthStr); err == nil {
 			strength = s
 		} else {
-			if combatLogger != nil {
-				combatLogger.Warn("Could not parse weapon strength",
+			if conflict.logger != nil {
+				conflict.logger.Warn("Could not parse weapon strength",
 					zap.String("strength_string", strengthStr),
 					zap.Error(err))
 			}
-			return 0, 0
+			return nil, 0
 		}
 	} else {
-		if combatLogger != nil {
-			combatLogger.Warn("No strength found for weapon")
+		if conflict.logger != nil {
+			conflict.logger.Warn("No strength found for weapon")
 		}
-		return 0, 0
+		return nil, 0
 	}
 
 	// Get target toughness
@@ -811,18 +1014,18 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 		if t, err := strconv.Atoi(toughnessStr); err == nil {
 			toughness = t
 		} else {
-			if combatLogger != nil {
-				combatLogger.Warn("Could not parse target toughness",
+			if conflict.logger != nil {
+				conflict.logger.Warn("Could not parse target toughness",
 					zap.String("toughness_string", toughnessStr),
 					zap.Error(err))
 			}
-			return 0, 0
+			return nil, 0
 		}
 	} else {
-		if combatLogger != nil {
-			combatLogger.Warn("No toughness found for target")
+		if conflict.logger != nil {
+			conflict.logger.Warn("No toughness found for target")
 		}
-		return 0, 0
+		return nil, 0
 	}
 
 	// Calculate wound threshold based on Strength vs Toughness
@@ -839,8 +1042,9 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 		woundThreshold = 5 // S < T: Need 5+
 	}
 
-	// Apply wound modifier
-	finalWoundThreshold := woundThreshold - weapon.Modifiers.WoundMod
+	// Apply wound modifier, including the defender's penalties, capped at +1 or -1
+	woundMod := capRollModifier(weapon.Modifiers.WoundMod + defense.woundMod(strength, toughness))
+	finalWoundThreshold := woundThreshold - woundMod
 	if finalWoundThreshold < 2 {
 		finalWoundThreshold = 2 // Minimum wound on 2+
 	}
@@ -851,21 +1055,25 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 	// Check if weapon has Devastating Wounds keyword
 	hasDevastatingWounds := strings.Contains(strings.ToLower(weapon.GetStringCharacteristic("Keywords")), "devastating wounds")
 
-	if combatLogger != nil {
-		combatLogger.Info("Wound Phase - Starting",
+	if conflict.logger != nil {
+		conflict.logger.Info("Wound Phase - Starting",
 			zap.Int("hits", hits),
 			zap.Int("lethal_hits", lethalHits),
 			zap.Int("weapon_strength", strength),
 			zap.Int("target_toughness", toughness),
 			zap.Int("wound_threshold", finalWoundThreshold),
 			zap.Int("base_threshold", woundThreshold),
-			zap.Int("wound_modifier", weapon.Modifiers.WoundMod),
+			zap.Int("wound_modifier", woundMod),
+			zap.Int("min_unmodified_roll", defense.MinWoundRoll),
 			zap.Bool("has_devastating_wounds", hasDevastatingWounds))
 	}
 
+	woundTriggers := conflict.weaponTriggers(weapon, "wound")
+
 	// Start with lethal hits that auto-wound
-	wounds := lethalHits
+	wounds := make([]woundRoll, lethalHits, hits)
 	criticalWounds := 0
+	mortalWounds := 0
 
 	// Process remaining hits that need wound rolls
 	normalHits := hits - lethalHits
@@ -875,9 +1083,10 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 
 	// Roll for wounds individually for non-lethal hits
 	for i := 0; i < normalHits; i++ {
-		roll := rollDice(1, 6)
-		wound := roll >= finalWoundThreshold
-		criticalWound := hasDevastatingWounds && roll >= weapon.Modifiers.CritWound
+		roll := rollDice(conflict.dice, 1, 6)
+		wound := roll >= finalWoundThreshold && roll >= defense.MinWoundRoll
+		critical := roll >= weapon.Modifiers.CritWound
+		criticalWound := hasDevastatingWounds && critical
 		rerolled := false
 
 		// Handle rerolls for misses
@@ -890,13 +1099,14 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 			}
 
 			if shouldReroll {
-				rerollResult := rollDice(1, 6)
-				wound = rerollResult >= finalWoundThreshold
-				criticalWound = hasDevastatingWounds && rerollResult >= weapon.Modifiers.CritWound
+				rerollResult := rollDice(conflict.dice, 1, 6)
+				wound = rerollResult >= finalWoundThreshold && rerollResult >= defense.MinWoundRoll
+				critical = rerollResult >= weapon.Modifiers.CritWound
+				criticalWound = hasDevastatingWounds && critical
 				rerolled = true
 
-				if combatLogger != nil {
-					combatLogger.Info("Wound Reroll",
+				if conflict.logger != nil {
+					conflict.logger.Info("Wound Reroll",
 						zap.Int("hit_number", i+1),
 						zap.Int("original_roll", roll),
 						zap.Int("reroll", rerollResult),
@@ -910,37 +1120,64 @@ func (conflict *UnitAttackSequence) rollWounds(hits int, weapon WeaponProfile, t
 		}
 
 		if wound {
-			wounds++
+			result := woundRoll{Devastating: criticalWound}
 			if criticalWound {
 				criticalWounds++
 			}
+
+			// Fire on-wound triggers such as extra damage, extra AP or mortal wounds
+			for _, trigger := range woundTriggers {
+				if !trigger.fires(roll, critical) {
+					continue
+				}
+				amount := trigger.amount(conflict.dice)
+				switch trigger.Effect {
+				case "mortal":
+					mortalWounds += amount
+				case "damage":
+					result.BonusDamage += amount
+				case "ap":
+					result.BonusAP += amount
+				}
+
+				if conflict.logger != nil {
+					conflict.logger.Info("Wound Trigger Fired",
+						zap.String("trigger", trigger.Source),
+						zap.Int("roll", roll),
+						zap.String("effect", trigger.Effect),
+						zap.Int("amount", amount))
+				}
+			}
+
+			wounds = append(wounds, result)
 		}
 
-		if combatLogger != nil {
-			combatLogger.Info("Wound Roll",
+		if conflict.logger != nil {
+			conflict.logger.Info("Wound Roll",
 				zap.Int("hit_number", i+1),
 				zap.Int("roll", roll),
 				zap.Int("threshold", finalWoundThreshold),
 				zap.Bool("wound", wound),
 				zap.Bool("devastating_wound", criticalWound),
 				zap.Bool("rerolled", rerolled),
-				zap.Int("running_wounds", wounds))
+				zap.Int("running_wounds", len(wounds)))
 		}
 	}
 
-	if combatLogger != nil {
-		combatLogger.Info("Wound Phase Complete",
-			zap.Int("total_wounds", wounds),
+	if conflict.logger != nil {
+		conflict.logger.Info("Wound Phase Complete",
+			zap.Int("total_wounds", len(wounds)),
 			zap.Int("devastating_wounds", criticalWounds),
-			zap.Int("lethal_hits_autowound", lethalHits))
+			zap.Int("lethal_hits_autowound", lethalHits),
+			zap.Int("triggered_mortal_wounds", mortalWounds))
 	}
 
-	return wounds, criticalWounds
+	return wounds, mortalWounds
 }
 
 // Save rolling method with detailed logging for each roll
-func (conflict *UnitAttackSequence) rollSaves(wounds int, criticalWounds int, weapon WeaponProfile, targetModel *ModelData, targetModelIndex int) int {
-	if wounds <= 0 {
+func (conflict *UnitAttackSequence) rollSaves(wounds []woundRoll, weapon WeaponProfile, targetModel *ModelData, targetModelIndex int) int {
+	if len(wounds) <= 0 {
 		return 0
 	}
 
@@ -963,117 +1200,150 @@ func (conflict *UnitAttackSequence) rollSaves(wounds int, criticalWounds int, we
 		damageStr = "1" // Default to 1 damage
 	}
 
-	// Get target saves
-	svStr := targetModel.Stats["SV"]
-	sv := 7 // Default to no save (7+ is impossible)
-	if svStr != "" {
-		svStr = strings.TrimSpace(svStr)
-		svStr = strings.Replace(svStr, "+", "", -1)
-		if svVal, err := strconv.Atoi(svStr); err == nil {
-			sv = svVal
+	criticalWounds := 0
+	for _, wound := range wounds {
+		if wound.Devastating {
+			criticalWounds++
 		}
 	}
 
-	isvStr := targetModel.Stats["ISV"]
-	isv := 7 // Default to no invuln save
-	if isvStr != "" {
-		isvStr = strings.TrimSpace(isvStr)
-		isvStr = strings.Replace(isvStr, "+", "", -1)
-		if isvVal, err := strconv.Atoi(isvStr); err == nil {
-			isv = isvVal
-		}
-	}
+	cover := conflict.hasBenefitOfCover(weapon)
+	saveModifiers := conflict.defenderSaveModifiers().against(weapon)
 
-	if combatLogger != nil {
-		combatLogger.Info("Save Phase - Starting",
-			zap.Int("wounds", wounds),
+	if conflict.logger != nil {
+		conflict.logger.Info("Save Phase - Starting",
+			zap.Int("wounds", len(wounds)),
 			zap.Int("devastating_wounds", criticalWounds),
-			zap.Int("armor_save", sv),
-			zap.Int("invulnerable_save", isv),
+			zap.Int("armor_save", targetModel.GetSaveCharacteristic("SV")),
+			zap.Int("invulnerable_save", targetModel.GetSaveCharacteristic("ISV")),
 			zap.Int("weapon_ap", ap),
-			zap.String("weapon_damage", damageStr))
+			zap.String("weapon_damage", damageStr),
+			zap.Bool("benefit_of_cover", cover),
+			zap.Strings("save_modifiers", saveModifiers.Sources),
+			zap.Strings("skipped_conditional_saves", saveModifiers.Skipped))
 	}
 
 	savedWounds := 0
 	devastatingDamage := 0
+	failedSaveDamage := 0
 
-	// Process critical wounds first (they bypass saves)
-	for i := 0; i < criticalWounds; i++ {
-		if combatLogger != nil {
-			combatLogger.Info("Devastating Wound",
-				zap.Int("wound_number", i+1),
-				zap.Bool("bypasses_save", true),
-				zap.String("damage_characteristic", damageStr))
+	// Process critical wounds first (they bypass saves), then the wounds that allow saves
+	ordered := make([]woundRoll, 0, len(wounds))
+	for _, wound := range wounds {
+		if wound.Devastating {
+			ordered = append(ordered, wound)
 		}
-
-		// Apply damage for Devastating Wound (no save allowed)
-		damageAmount := conflict.applyDamage(targetModelIndex, damageStr, "devastating")
-		devastatingDamage += damageAmount
-
-		if combatLogger != nil {
-			combatLogger.Info("Devastating Wound Damage Applied",
-				zap.String("damage_amount", damageStr),
-				zap.Int("actual_damage", damageAmount),
-				zap.Int("target_model_index", targetModelIndex),
-				zap.String("target_model_name", targetModel.Name),
-				zap.Bool("devastating_wound", true))
+	}
+	for _, wound := range wounds {
+		if !wound.Devastating {
+			ordered = append(ordered, wound)
 		}
 	}
 
-	// Process remaining wounds that allow saves
-	normalWounds := wounds - criticalWounds
-	if normalWounds < 0 {
-		normalWounds = 0
-	}
+	for i, wound := range ordered {
+		// Allocate to the next model group once the current one is wiped out
+		if targetModel.Killed >= targetModel.Count {
+			if targetModelIndex = conflict.allocationTarget(); targetModelIndex < 0 {
+				if conflict.logger != nil {
+					conflict.logger.Info("Save Phase - No alive models remain, discarding remaining wounds",
+						zap.Int("discarded_wounds", len(ordered)-i))
+				}
+				break
+			}
+			targetModel = &conflict.Defender.Models[targetModelIndex]
+		}
 
-	failedSaveDamage := 0
-	for i := 0; i < normalWounds; i++ {
-		roll := rollDice(1, 6)
-		saved := false
-		saveType := ""
-		saveUsed := 0
-
-		// Check invulnerable save first (not modified by AP)
-		if isv <= 6 && roll >= isv {
-			saved = true
+		bonusDamage := fmt.Sprintf("bonus damage %d", wound.BonusDamage)
+		attackType := "ranged"
+		if strings.Contains(strings.ToLower(weapon.Type), "melee") {
+			attackType = "melee"
+		}
+
+		if wound.Devastating {
+			if conflict.logger != nil {
+				conflict.logger.Info("Devastating Wound",
+					zap.Int("wound_number", i+1),
+					zap.Bool("bypasses_save", true),
+					zap.String("damage_characteristic", damageStr),
+					zap.Int("bonus_damage", wound.BonusDamage))
+			}
+
+			// Apply damage for Devastating Wound (no save allowed)
+			damageAmount := conflict.applyDamage(targetModelIndex, damageStr, "devastating", bonusDamage, attackType)
+			devastatingDamage += damageAmount
+
+			if conflict.logger != nil {
+				conflict.logger.Info("Devastating Wound Damage Applied",
+					zap.String("damage_amount", damageStr),
+					zap.Int("actual_damage", damageAmount),
+					zap.Int("target_model_index", targetModelIndex),
+					zap.String("target_model_name", targetModel.Name),
+					zap.Bool("devastating_wound", true))
+			}
+			continue
+		}
+
+		// Final saves after AP, triggered AP, save modifiers and cover
+		armourSave, invulnerableSave := saveModifiers.saveThresholds(
+			targetModel.GetSaveCharacteristic("SV"),
+			targetModel.GetSaveCharacteristic("ISV"),
+			ap+wound.BonusAP,
+			cover)
+
+		// Use whichever save is better, invulnerable saves win ties
+		saveType := "armor"
+		saveUsed := armourSave
+		if invulnerableSave <= armourSave {
 			saveType = "invulnerable"
-			saveUsed = isv
-		} else {
-			// Check armor save (modified by AP)
-			modifiedSv := sv + ap
-			if modifiedSv <= 6 && roll >= modifiedSv {
-				saved = true
-				saveType = "armor"
-				saveUsed = modifiedSv
+			saveUsed = invulnerableSave
+		}
+
+		roll := rollDice(conflict.dice, 1, 6)
+		saved := saveUsed <= 6 && roll >= saveUsed
+		rerolled := false
+
+		// Handle save rerolls for failed saves
+		if !saved && saveUsed <= 6 && (saveModifiers.RerollSaves || (saveModifiers.RerollSave1s && roll == 1)) {
+			rerollResult := rollDice(conflict.dice, 1, 6)
+			saved = rerollResult >= saveUsed
+			rerolled = true
+
+			if conflict.logger != nil {
+				conflict.logger.Info("Save Reroll",
+					zap.Int("wound_number", i+1),
+					zap.Int("original_roll", roll),
+					zap.Int("reroll", rerollResult),
+					zap.Int("save_threshold", saveUsed),
+					zap.Bool("saved", saved))
 			}
+
+			roll = rerollResult // Update roll for logging
+		}
+
+		if conflict.logger != nil {
+			conflict.logger.Info("Save Roll",
+				zap.Int("wound_number", i+1),
+				zap.Int("roll", roll),
+				zap.String("save_type", saveType),
+				zap.Int("save_threshold", saveUsed),
+				zap.Int("armor_save", armourSave),
+				zap.Int("invulnerable_save", invulnerableSave),
+				zap.Int("bonus_ap", wound.BonusAP),
+				zap.Bool("rerolled", rerolled),
+				zap.Bool("saved", saved))
 		}
 
 		if saved {
 			savedWounds++
-			if combatLogger != nil {
-				combatLogger.Info("Save Roll",
-					zap.Int("wound_number", i+1+criticalWounds),
-					zap.Int("roll", roll),
-					zap.String("save_type", saveType),
-					zap.Int("save_threshold", saveUsed),
-					zap.Bool("saved", true))
-			}
+			conflict.tally.Saves++
 		} else {
-			if combatLogger != nil {
-				combatLogger.Info("Save Roll",
-					zap.Int("wound_number", i+1+criticalWounds),
-					zap.Int("roll", roll),
-					zap.String("save_type", "failed"),
-					zap.Int("save_threshold", 0),
-					zap.Bool("saved", false))
-			}
-
+			conflict.tally.FailedSaves++
 			// Apply damage for failed save
-			damageAmount := conflict.applyDamage(targetModelIndex, damageStr)
+			damageAmount := conflict.applyDamage(targetModelIndex, damageStr, bonusDamage, attackType)
 			failedSaveDamage += damageAmount
 
-			if combatLogger != nil {
-				combatLogger.Info("Normal Wound Damage Applied",
+			if conflict.logger != nil {
+				conflict.logger.Info("Normal Wound Damage Applied",
 					zap.String("damage_amount", damageStr),
 					zap.Int("actual_damage", damageAmount),
 					zap.Int("target_model_index", targetModelIndex),
@@ -1085,8 +1355,8 @@ func (conflict *UnitAttackSequence) rollSaves(wounds int, criticalWounds int, we
 
 	totalDamageApplied := devastatingDamage + failedSaveDamage
 
-	if combatLogger != nil {
-		combatLogger.Info("Save Phase Complete",
+	if conflict.logger != nil {
+		conflict.logger.Info("Save Phase Complete",
 			zap.Int("total_damage_applied", totalDamageApplied),
 			zap.Int("devastating_wound_damage", devastatingDamage),
 			zap.Int("failed_save_damage", failedSaveDamage),
@@ -1096,53 +1366,104 @@ func (conflict *UnitAttackSequence) rollSaves(wounds int, criticalWounds int, we
 	return totalDamageApplied
 }
 
-// Apply abilities and weapon keywords that modify combat characteristics
-func (conflict *UnitAttackSequence) applyAbilities() {
-	if combatLogger != nil {
-		combatLogger.Info("Starting ability processing",
+// Copy a weapon profile so abilities can modify it without touching the unit template
+func (w WeaponProfile) clone() WeaponProfile {
+	characteristics := make(map[string]string, len(w.Characteristics))
+	for name, value := range w.Characteristics {
+		characteristics[name] = value
+	}
+	w.Characteristics = characteristics
+	return w
+}
+
+// Return the attacker's weapons with abilities applied, deriving them once per matchup
+func (conflict *UnitAttackSequence) attackerWeapons() *weaponView {
+	if conflict.views == nil {
+		conflict.views = &weaponViews{}
+	}
+
+	// Battle-shocked units cannot use stratagems, and each is used only once per combat, so
+	// units without them get a separate view
+	stratagems := !conflict.Attacker.BattleShocked && !conflict.Attacker.StratagemUsed
+	view := &conflict.views.withoutStratagems
+	if stratagems {
+		view = &conflict.views.withStratagems
+	}
+	if *view == nil {
+		*view = conflict.deriveWeaponView(stratagems)
+	}
+	return *view
+}
+
+// Apply abilities and weapon keywords that modify combat characteristics to copies of the attacker's weapons
+func (conflict *UnitAttackSequence) deriveWeaponView(stratagems bool) *weaponView {
+	view := &weaponView{
+		Loadouts: make([]map[string]WeaponProfile, len(conflict.Attacker.Models)),
+		Defense:  conflict.defenderModifiers(),
+	}
+	for modelIndex, model := range conflict.Attacker.Models {
+		view.Loadouts[modelIndex] = make(map[string]WeaponProfile, len(model.Loadouts))
+		for weaponName, weapon := range model.Loadouts {
+			view.Loadouts[modelIndex][weaponName] = weapon.clone()
+		}
+	}
+
+	if conflict.logger != nil {
+		conflict.logger.Info("Starting ability processing",
 			zap.String("attacker_unit", conflict.Attacker.Name),
 			zap.Strings("attacker_abilities", conflict.Attacker.Abilities))
 	}
 
 	// Process attacker abilities
 	for _, ability := range conflict.Attacker.Abilities {
+		// Battle-shocked units cannot use stratagems, nor can units that already used them
+		if isStratagem(ability) {
+			if !stratagems {
+				if conflict.logger != nil {
+					conflict.logger.Info(fmt.Sprintf("Skipped %s: %s is Battle-shocked or has already used it this combat", ability, conflict.Attacker.Name))
+				}
+				continue
+			}
+			view.UsesStratagem = true
+		}
+
 		switch strings.ToLower(ability) {
 		case "oath of moment":
 			// Set reroll hits for all loadouts
 			weaponsModified := 0
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
 					if !weapon.Modifiers.RerollHits { // Only modify if not already set
 						weapon.Modifiers.RerollHits = true
-						conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+						view.Loadouts[modelIndex][weaponName] = weapon
 						weaponsModified++
 
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Applied Oath of Moment to %s (%s): RerollHits = true (was false)",
+						if conflict.logger != nil {
+							conflict.logger.Info(fmt.Sprintf("Applied Oath of Moment to %s (%s): RerollHits = true (was false)",
 								weaponName,
 								conflict.Attacker.Models[modelIndex].Name))
 						}
 					}
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied Oath of Moment: Modified %d weapons", weaponsModified))
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied Oath of Moment: Modified %d weapons", weaponsModified))
 			}
 
 		case "stationary":
 			// Heavy weapons get +1 to hit when stationary
 			heavyWeaponsModified := 0
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
 					keywords := strings.ToLower(weapon.GetStringCharacteristic("Keywords"))
 					if strings.Contains(keywords, "heavy") {
 						previousHitMod := weapon.Modifiers.HitMod
 						weapon.Modifiers.HitMod += 1
-						conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+						view.Loadouts[modelIndex][weaponName] = weapon
 						heavyWeaponsModified++
 
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Applied Stationary to %s (%s): HitMod += 1 (was %d, now %d)",
+						if conflict.logger != nil {
+							conflict.logger.Info(fmt.Sprintf("Applied Stationary to %s (%s): HitMod += 1 (was %d, now %d)",
 								weaponName,
 								conflict.Attacker.Models[modelIndex].Name,
 								previousHitMod,
@@ -1151,33 +1472,33 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 					}
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied Stationary: Modified %d heavy weapons", heavyWeaponsModified))
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied Stationary: Modified %d heavy weapons", heavyWeaponsModified))
 			}
 
 		case "rapid fire distance":
 			// Rapid Fire weapons get additional shots equal to their rapid fire value
 			rapidFireWeaponsModified := 0
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
 					keywords := strings.ToLower(weapon.GetStringCharacteristic("Keywords"))
 					if strings.Contains(keywords, "rapid fire") {
 						// Parse rapid fire value - look for "rapid fire X"
 						keywordParts := strings.Fields(keywords)
 						for j, part := range keywordParts {
 							if part == "rapid" && j+2 < len(keywordParts) && keywordParts[j+1] == "fire" {
-								if rapidFireValue, err := strconv.Atoi(keywordParts[j+2]); err == nil {
+								if rapidFireValue, err := strconv.Atoi(strings.TrimSuffix(keywordParts[j+2], ",")); err == nil {
 									// Get current attacks and add rapid fire bonus
 									attacksStr := weapon.GetStringCharacteristic("A")
 									if attacksStr != "" {
 										if currentAttacks, err := strconv.Atoi(attacksStr); err == nil {
 											newAttacks := currentAttacks + rapidFireValue
 											weapon.Characteristics["A"] = strconv.Itoa(newAttacks)
-											conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+											view.Loadouts[modelIndex][weaponName] = weapon
 											rapidFireWeaponsModified++
 
-											if combatLogger != nil {
-												combatLogger.Info(fmt.Sprintf("Applied Rapid Fire Distance to %s (%s): Attacks += %d (was %d, now %d)",
+											if conflict.logger != nil {
+												conflict.logger.Info(fmt.Sprintf("Applied Rapid Fire Distance to %s (%s): Attacks += %d (was %d, now %d)",
 													weaponName,
 													conflict.Attacker.Models[modelIndex].Name,
 													rapidFireValue,
@@ -1193,8 +1514,8 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 					}
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied Rapid Fire Distance: Modified %d rapid fire weapons", rapidFireWeaponsModified))
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied Rapid Fire Distance: Modified %d rapid fire weapons", rapidFireWeaponsModified))
 			}
 
 		case "red rampage":
@@ -1204,17 +1525,13 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 			// RED RAMPAGE ONLY AFFECTS MELEE WEAPONS (Fight Phase stratagem)
 			weaponsModified := 0
 
-			// Add Charged ability to enable Lance keyword
-			exists, _ := stringExistsInSlice("Charged", conflict.Attacker.Abilities)
-			if !exists {
-				conflict.Attacker.Abilities = append(conflict.Attacker.Abilities, "Charged")
-				if combatLogger != nil {
-					combatLogger.Info(fmt.Sprintf("Added Charged ability to %s: Enables Lance keyword", conflict.Attacker.Name))
-				}
+			// Red Rampage counts the unit as Charged, enabling the Lance keyword
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Red Rampage counts %s as Charged: Enables Lance keyword", conflict.Attacker.Name))
 			}
 
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
 					// Only apply to melee weapons
 					weaponType := strings.ToLower(weapon.Type)
 					if !strings.Contains(weaponType, "melee") {
@@ -1250,79 +1567,67 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 						weapon.Modifiers.WoundMod = 1
 					}
 
-					conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+					view.Loadouts[modelIndex][weaponName] = weapon
 					weaponsModified++
 
-					if combatLogger != nil {
-						combatLogger.Info(fmt.Sprintf("Applied Red Rampage to %s (%s): Added Lethal Hits + Lance (melee only), WoundMod = %d, Unit Battle-shocked",
+					if conflict.logger != nil {
+						conflict.logger.Info(fmt.Sprintf("Applied Red Rampage to %s (%s): Added Lethal Hits + Lance (melee only), WoundMod = %d, Unit Battle-shocked",
 							weaponName,
 							conflict.Attacker.Models[modelIndex].Name,
 							weapon.Modifiers.WoundMod))
 					}
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied Red Rampage: Modified %d melee weapons", weaponsModified))
+
+			// Giving into the rampage leaves the unit Battle-shocked
+			view.BattleShocksUser = true
+
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied Red Rampage: Modified %d melee weapons", weaponsModified))
 			}
 
 		case "crithitfish":
 			critFishWeaponsModified := 0
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
 					if !weapon.Modifiers.CritHitFish {
 						weapon.Modifiers.CritHitFish = true
-						conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+						view.Loadouts[modelIndex][weaponName] = weapon
 						critFishWeaponsModified++
 
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Applied CritHitFish to %s (%s): CritHitFish = true",
+						if conflict.logger != nil {
+							conflict.logger.Info(fmt.Sprintf("Applied CritHitFish to %s (%s): CritHitFish = true",
 								weaponName,
 								conflict.Attacker.Models[modelIndex].Name))
 						}
 					}
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied CritHitFish: Modified %d weapons", critFishWeaponsModified))
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied CritHitFish: Modified %d weapons", critFishWeaponsModified))
 			}
-		}
-	}
-
-	// Process defender abilities
-	for _, ability := range conflict.Defender.Abilities {
-		switch strings.ToLower(ability) {
-		case "stealth":
-			// Stealth subtracts 1 from hit rolls for ranged attacks
-			rangedWeaponsModified := 0
-			for modelIndex := range conflict.Attacker.Models {
-				for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
-					weaponType := strings.ToLower(weapon.Type)
-					if strings.Contains(weaponType, "ranged") {
-						previousHitMod := weapon.Modifiers.HitMod
-						weapon.Modifiers.HitMod -= 1
-						conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
-						rangedWeaponsModified++
 
-						if combatLogger != nil {
-							combatLogger.Info(fmt.Sprintf("Applied Stealth to %s (%s): HitMod -= 1 (was %d, now %d)",
-								weaponName,
-								conflict.Attacker.Models[modelIndex].Name,
-								previousHitMod,
-								weapon.Modifiers.HitMod))
-						}
-					}
+		default:
+			// Single weapon buffs such as "+1 to hit" or "lethal hits", see buffs.go
+			buff, isBuff := weaponBuffFor(ability)
+			if !isBuff {
+				break
+			}
+			for modelIndex := range view.Loadouts {
+				for weaponName, weapon := range view.Loadouts[modelIndex] {
+					view.Loadouts[modelIndex][weaponName] = buff.Apply(weapon)
 				}
 			}
-			if combatLogger != nil {
-				combatLogger.Info(fmt.Sprintf("Applied Stealth: Modified %d ranged weapons", rangedWeaponsModified))
+			if conflict.logger != nil {
+				conflict.logger.Info(fmt.Sprintf("Applied %s to every weapon of %s", buff.Label, conflict.Attacker.Name))
 			}
 		}
 	}
 
 	// Process weapon-specific abilities (Twin-linked)
 	twinLinkedWeaponsModified := 0
-	for modelIndex := range conflict.Attacker.Models {
-		for weaponName, weapon := range conflict.Attacker.Models[modelIndex].Loadouts {
+	for modelIndex := range view.Loadouts {
+		for weaponName, weapon := range view.Loadouts[modelIndex] {
 			weaponNameLower := strings.ToLower(weaponName)
 			keywords := strings.ToLower(weapon.GetStringCharacteristic("Keywords"))
 
@@ -1330,11 +1635,11 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 			if strings.Contains(weaponNameLower, "twin-linked") || strings.Contains(keywords, "twin-linked") {
 				if !weapon.Modifiers.RerollWounds { // Only modify if not already set
 					weapon.Modifiers.RerollWounds = true
-					conflict.Attacker.Models[modelIndex].Loadouts[weaponName] = weapon
+					view.Loadouts[modelIndex][weaponName] = weapon
 					twinLinkedWeaponsModified++
 
-					if combatLogger != nil {
-						combatLogger.Info(fmt.Sprintf("Applied Twin-linked to %s (%s): RerollWounds = true (was false)",
+					if conflict.logger != nil {
+						conflict.logger.Info(fmt.Sprintf("Applied Twin-linked to %s (%s): RerollWounds = true (was false)",
 							weaponName,
 							conflict.Attacker.Models[modelIndex].Name))
 					}
@@ -1343,9 +1648,11 @@ func (conflict *UnitAttackSequence) applyAbilities() {
 		}
 	}
 
-	if combatLogger != nil {
-		combatLogger.Info(fmt.Sprintf("Ability processing complete for %s: Modified %d twin-linked weapons",
+	if conflict.logger != nil {
+		conflict.logger.Info(fmt.Sprintf("Ability processing complete for %s: Modified %d twin-linked weapons",
 			conflict.Attacker.Name,
 			twinLinkedWeaponsModified))
 	}
+
+	return view
 }
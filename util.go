@@ -1,7 +1,6 @@
 package main
 
 import (
-	"math/rand"
 	"regexp"
 	"strconv"
 	"strings"
@@ -21,7 +20,13 @@ func remove(slice []string, s int) []string {
 	return append(slice[:s], slice[s+1:]...)
 }
 
-func rollAndAdd(input string) (int, error) {
+// DiceSource supplies the random numbers behind every dice roll. *rand.Rand satisfies it,
+// so each simulation worker can own an independent generator.
+type DiceSource interface {
+	Intn(n int) int
+}
+
+func rollAndAdd(dice DiceSource, input string) (int, error) {
 	re := regexp.MustCompile(`(\d*)d(\d+)(\s*([+-])\s*(\d+))?`)
 	matches := re.FindStringSubmatch(input)
 
@@ -41,12 +46,21 @@ func rollAndAdd(input string) (int, error) {
 	}
 	numberOfDice, _ := strconv.Atoi(numberOfDiceStr)
 	modifier, _ := strconv.Atoi(modifierStr)
-	return rollDice(numberOfDice, diceType) + modifier, nil
+	return rollDice(dice, numberOfDice, diceType) + modifier, nil
+}
+
+// Roll a characteristic that may be a flat number or dice notation like "D6+1"
+func rollExpression(dice DiceSource, expr string) (int, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if strings.HasPrefix(expr, "d") {
+		expr = "1" + expr
+	}
+	return rollAndAdd(dice, expr)
 }
-func rollDice(numberOfDice, diceType int) int {
+func rollDice(dice DiceSource, numberOfDice, diceType int) int {
 	total := 0
 	for i := 0; i < numberOfDice; i++ {
-		roll := rand.Intn(diceType) + 1
+		roll := dice.Intn(diceType) + 1
 		total = total + roll
 	}
 	return total
@@ -57,3 +71,13 @@ func abilityCheck(ability string, list []string) bool {
 	}
 	return false
 }
+
+// Parse a roll target like "3+" into 3, returning 7 (impossible) if it cannot be parsed
+func parseRollThreshold(value string) int {
+	value = strings.TrimSpace(value)
+	value = strings.Replace(value, "+", "", -1)
+	if threshold, err := strconv.Atoi(value); err == nil {
+		return threshold
+	}
+	return 7
+}
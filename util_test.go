@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestParseRollThreshold(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"3+", 3},
+		{" 4+ ", 4},
+		{"2", 2},
+		{"", 7},
+		{"-", 7},
+		{"N/A", 7},
+	}
+	for _, test := range tests {
+		if got := parseRollThreshold(test.value); got != test.want {
+			t.Errorf("parseRollThreshold(%q) = %d, want %d", test.value, got, test.want)
+		}
+	}
+}